@@ -1,6 +1,8 @@
 package conditioning
 
 import (
+	"time"
+
 	. "gopkg.in/check.v1" // https://labix.org/gocheck
 )
 
@@ -44,21 +46,26 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 		This is cool [    b:32:12,-34   something.jpeg:3.23:12,-34   ]
 		`
 
-	affirmations, title := parseAffirmations(text)
+	affirmations, title, diagnostics := parseAffirmations("affirmations.txt", text)
 	c.Check(title, Equals, "A fun title.")
+	c.Check(diagnostics, IsNil)
 	c.Check(affirmations, DeepEquals, []Affirmation{
 		Affirmation{
+			Line:    6,
 			Message: "here is an affirmation!",
 		},
 		Affirmation{
+			Line:    7,
 			Message: "another one...",
 		},
 		Affirmation{
+			Line:    10,
 			Message: "yay!",
 		},
 
 		// Images.
 		Affirmation{
+			Line:    13,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -68,6 +75,7 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 			},
 		},
 		Affirmation{
+			Line:    14,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -77,6 +85,7 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 			},
 		},
 		Affirmation{
+			Line:    15,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -86,6 +95,7 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 			},
 		},
 		Affirmation{
+			Line:    16,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -95,6 +105,7 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 			},
 		},
 		Affirmation{
+			Line:    17,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -106,45 +117,50 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 
 		// Text.
 		Affirmation{
+			Line:    20,
 			Message: "This is cool",
 			Text: TextProperties{
-				Black:    BLACK,
+				Color:    BLACK,
 				OffsetX:  0,
 				OffsetY:  0,
 				FontSize: 0,
 			},
 		},
 		Affirmation{
+			Line:    21,
 			Message: "This is cool",
 			Text: TextProperties{
-				Black:    WHITE,
+				Color:    WHITE,
 				OffsetX:  0,
 				OffsetY:  0,
 				FontSize: 32,
 			},
 		},
 		Affirmation{
+			Line:    22,
 			Message: "This is cool",
 			Text: TextProperties{
-				Black:    BLACK,
+				Color:    BLACK,
 				OffsetX:  12,
 				OffsetY:  -34,
 				FontSize: 0,
 			},
 		},
 		Affirmation{
+			Line:    23,
 			Message: "This is cool",
 			Text: TextProperties{
-				Black:    WHITE,
+				Color:    WHITE,
 				OffsetX:  12,
 				OffsetY:  -34,
 				FontSize: 32,
 			},
 		},
 		Affirmation{
+			Line:    24,
 			Message: "This is cool",
 			Text: TextProperties{
-				Black:    BLACK,
+				Color:    BLACK,
 				OffsetX:  12,
 				OffsetY:  -34,
 				FontSize: 32,
@@ -153,6 +169,7 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 
 		// Image and text.
 		Affirmation{
+			Line:    27,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -161,13 +178,14 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 				Scale:    3.23,
 			},
 			Text: TextProperties{
-				Black:    BLACK,
+				Color:    BLACK,
 				OffsetX:  12,
 				OffsetY:  -34,
 				FontSize: 32,
 			},
 		},
 		Affirmation{
+			Line:    28,
 			Message: "This is cool",
 			Image: AffirmationImage{
 				Filename: "something.jpeg",
@@ -176,7 +194,7 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 				Scale:    3.23,
 			},
 			Text: TextProperties{
-				Black:    BLACK,
+				Color:    BLACK,
 				OffsetX:  12,
 				OffsetY:  -34,
 				FontSize: 32,
@@ -184,3 +202,412 @@ func (s *AffirmationSuite) Test_Validate(c *C) {
 		},
 	})
 }
+
+func (s *AffirmationSuite) Test_ParseDiagnostics(c *C) {
+
+	// A sample file with mistakes.
+	text := "// A fun title.\n" +
+		"This is fine [b:32]\n" +
+		"  Bad font [b:4S]\n" +
+		"Bad offset [pic.jpg:1,x]\n" +
+		"Bad scale [pic.jpg:big w:1,2,3]\n" +
+		"Unknown [ bb  pic.jpg ]\n"
+
+	affirmations, title, diagnostics := parseAffirmations("affirmations.txt", text)
+	c.Check(title, Equals, "A fun title.")
+	c.Check(len(affirmations), Equals, 5)
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 3, Column: 15, Severity: SEVERITY_ERROR, Message: "invalid font size", Token: "4S"},
+		{File: "affirmations.txt", Line: 4, Column: 21, Severity: SEVERITY_ERROR, Message: "invalid offset, expected x,y", Token: "1,x"},
+		{File: "affirmations.txt", Line: 5, Column: 20, Severity: SEVERITY_ERROR, Message: "invalid image scale", Token: "big"},
+		{File: "affirmations.txt", Line: 5, Column: 26, Severity: SEVERITY_ERROR, Message: "invalid offset, expected x,y", Token: "1,2,3"},
+		{File: "affirmations.txt", Line: 6, Column: 11, Severity: SEVERITY_WARNING, Message: "unknown display token", Token: "bb"},
+	})
+	c.Check(countErrors(diagnostics), Equals, 4)
+
+	// Bad values fall back to the defaults.
+	c.Check(affirmations[1].Text, DeepEquals, TextProperties{Color: BLACK})
+	c.Check(affirmations[2].Image, DeepEquals, AffirmationImage{Filename: "pic.jpg", Scale: 1.0})
+	c.Check(diagnostics[0].String(), Equals, `affirmations.txt:3:15: error: invalid font size: '4S'`)
+}
+
+func (s *AffirmationSuite) Test_ParseDisplayGroups(c *C) {
+
+	// Each display group adds to the ones before it, and problems are found in any of them.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "Price 1/2 [pic.jpg b] [t:8s w:4S]")
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 1, Column: 31, Severity: SEVERITY_ERROR, Message: "invalid font size", Token: "4S"},
+	})
+	c.Check(affirmations[0].Message, Equals, "Price 1/2")
+	c.Check(affirmations[0].Image.Filename, Equals, "pic.jpg")
+	c.Check(affirmations[0].Text.Color, Equals, WHITE)
+	c.Check(affirmations[0].Duration, Equals, 8*time.Second)
+}
+
+func (s *AffirmationSuite) Test_ParseDuration(c *C) {
+	tests := []struct {
+		text     string
+		duration time.Duration
+		parsed   bool
+		errors   int
+	}{
+		{"t:8s", 8 * time.Second, true, 0},
+		{"t:1500ms", 1500 * time.Millisecond, true, 0},
+		{"t:1m", time.Minute, true, 0},
+		{"t", 0, true, 1},
+		{"t:8", 0, true, 1},
+		{"t:-8s", 0, true, 1},
+		{"t:8s:2", 0, true, 1},
+		{"b:32", 0, false, 0},
+		{"pic.jpg", 0, false, 0},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		duration, parsed, diagnostics := parseDuration(test.text, 1)
+		c.Check(duration, Equals, test.duration, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// The duration sits alongside the other display settings.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "Slow down [pic.jpg t:8s b]")
+	c.Check(diagnostics, IsNil)
+	c.Check(affirmations, DeepEquals, []Affirmation{{
+		Line:     1,
+		Message:  "Slow down",
+		Image:    AffirmationImage{Filename: "pic.jpg", Scale: 1.0},
+		Text:     TextProperties{Color: BLACK},
+		Duration: 8 * time.Second,
+	}})
+}
+
+func (s *AffirmationSuite) Test_ParseWeight(c *C) {
+	tests := []struct {
+		text   string
+		weight uint
+		parsed bool
+		errors int
+	}{
+		{"x3", 3, true, 0},
+		{"x1", 1, true, 0},
+		{"x", 0, true, 1},
+		{"x0", 0, true, 1},
+		{"x-2", 0, true, 1},
+		{"xyz", 0, true, 1},
+		{"x1.5", 0, true, 1},
+		{"b:32", 0, false, 0},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		weight, parsed, diagnostics := parseWeight(test.text, 1)
+		c.Check(weight, Equals, test.weight, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// The weight sits alongside the other display settings.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "Key idea [x3 b]\nOther idea")
+	c.Check(diagnostics, IsNil)
+	c.Check(affirmations[0].Weight, Equals, uint(3))
+	c.Check(affirmations[0].weight(), Equals, 3)
+	c.Check(affirmations[1].weight(), Equals, 1)
+
+	// A weight that isn't a whole number is reported, not taken for an image.
+	affirmations, _, diagnostics = parseAffirmations("affirmations.txt", "Key idea [x1.5 xmas.jpg]")
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 1, Column: 11, Severity: SEVERITY_ERROR, Message: "invalid weight, expected x3", Token: "x1.5"},
+	})
+	c.Check(affirmations[0].Image.Filename, Equals, "xmas.jpg")
+	c.Check(affirmations[0].Weight, Equals, uint(0))
+}
+
+func (s *AffirmationSuite) Test_ParseID(c *C) {
+	tests := []struct {
+		text   string
+		id     string
+		parsed bool
+		errors int
+	}{
+		{"#calm", "calm", true, 0},
+		{"#morning-1", "morning-1", true, 0},
+		{"#big_idea", "big_idea", true, 0},
+		{"#", "", true, 1},
+		{"#a.b", "", true, 1},
+		{"#a#b", "", true, 1},
+		{"x3", "", false, 0},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		id, parsed, diagnostics := parseID(test.text, 1)
+		c.Check(id, Equals, test.id, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// The id sits alongside the other display settings, and may only be used once.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "I am calm [#calm b]\nI am kind\nI am still [#calm]")
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 3, Column: 13, Severity: SEVERITY_ERROR, Message: "duplicate id, first on line 1", Token: "#calm"},
+	})
+	c.Check(affirmations[0].ID, Equals, "calm")
+	c.Check(affirmations[2].ID, Equals, "")
+
+	// An id with a "." is reported, not taken for an image.
+	dotted, _, dottedDiagnostics := parseAffirmations("affirmations.txt", "I am calm [#my.id]")
+	c.Check(dottedDiagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 1, Column: 12, Severity: SEVERITY_ERROR, Message: "invalid id, expected #name", Token: "#my.id"},
+	})
+	c.Check(dotted[0].Image, Equals, AffirmationImage{})
+
+	// An id keeps the identity when the message changes, otherwise the message is the identity.
+	c.Check(affirmations[0].identity(), Equals, "#calm")
+	c.Check(Affirmation{Message: "I am calm.", ID: "calm"}.identity(), Equals, "#calm")
+	c.Check(affirmations[1].identity(), Equals, Affirmation{Message: "I am kind"}.identity())
+	c.Check(affirmations[1].identity(), Not(Equals), affirmations[2].identity())
+
+	// A repeated message is told apart from the first.
+	repeated := []Affirmation{{Message: "I am kind"}, {Message: "I am calm"}, {Message: "I am kind"}}
+	c.Check(identities(repeated)[0], Equals, repeated[0].identity())
+	c.Check(identities(repeated)[2], Not(Equals), identities(repeated)[0])
+	index, found := findIdentity(repeated, identities(repeated)[2])
+	c.Check(index, Equals, 2)
+	c.Check(found, Equals, true)
+
+	// Finding an affirmation by identity.
+	index, found = findIdentity(affirmations, affirmations[2].identity())
+	c.Check(index, Equals, 2)
+	c.Check(found, Equals, true)
+	_, found = findIdentity(affirmations, "#kind")
+	c.Check(found, Equals, false)
+}
+
+func (s *AffirmationSuite) Test_ParseWrap(c *C) {
+	tests := []struct {
+		text       string
+		textLayout TextLayout
+		parsed     bool
+		problems   int
+	}{
+		{"wrap:800", TextLayout{MaxWidth: 800}, true, 0},
+		{"wrap:800x300", TextLayout{MaxWidth: 800, MaxHeight: 300}, true, 1},
+		{"wrap:800x300:nofit", TextLayout{MaxWidth: 800, MaxHeight: 300, NoShrink: true}, true, 1},
+		{"wrap:800:nofit", TextLayout{MaxWidth: 800, NoShrink: true}, true, 0},
+		{"wrap:800:nofit:fit", TextLayout{MaxWidth: 800, ShrinkToFit: true}, true, 0},
+		{"wrap:800:justify", TextLayout{MaxWidth: 800, Align: TEXT_ALIGN_JUSTIFY}, true, 0},
+		{"wrap:800x300:left:fit", TextLayout{MaxWidth: 800, MaxHeight: 300, Align: TEXT_ALIGN_LEFT, ShrinkToFit: true}, true, 0},
+		{"wrap:800:middle", TextLayout{MaxWidth: 800}, true, 1},
+		{"wrap", TextLayout{}, true, 1},
+		{"wrap:", TextLayout{}, true, 1},
+		{"wrap:0", TextLayout{}, true, 1},
+		{"wrap:800x", TextLayout{}, true, 1},
+		{"wrap:800x300x2", TextLayout{}, true, 1},
+		{"w:32", TextLayout{}, false, 0},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		textLayout, parsed, diagnostics := parseWrap(test.text, 1)
+		c.Check(textLayout, Equals, test.textLayout, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.problems, comment)
+	}
+
+	// A height without shrinking to fit is reported.
+	_, _, diagnostics := parseWrap("wrap:800x300:left", 1)
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{Column: 6, Severity: SEVERITY_WARNING, Message: "wrap height is only used when shrinking to fit, add :fit", Token: "800x300"},
+	})
+
+	// The wrapping sits alongside the text settings, in any order.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "I am calm [wrap:600:right b:32]\nI am kind [w wrap:600:centre]")
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 2, Column: 23, Severity: SEVERITY_ERROR, Message: "invalid wrap, expected wrap:width[xheight][:left|center|right|justify][:fit|:nofit]", Token: "centre"},
+	})
+	c.Check(affirmations[0].Text, Equals, TextProperties{Color: BLACK, FontSize: 32, Layout: TextLayout{MaxWidth: 600, Align: TEXT_ALIGN_RIGHT}})
+	c.Check(affirmations[1].Text, Equals, TextProperties{Color: WHITE, Layout: TextLayout{MaxWidth: 600}})
+}
+
+func (s *AffirmationSuite) Test_ParseColors(c *C) {
+
+	// Text colors.
+	textTests := []struct {
+		text   string
+		color  string
+		parsed bool
+		errors int
+	}{
+		{"b", BLACK, true, 0},
+		{"w:32", WHITE, true, 0},
+		{"c:gold", "gold", true, 0},
+		{"c:#ffcc00:32:12,-34", "#ffcc00", true, 0},
+		{"c:#ffcc0080", "#ffcc0080", true, 0},
+		{"c:auto", AUTO, true, 0},
+		{"c", "", true, 1},
+		{"c:beige", "", true, 1},
+		{"c:gold:x", "gold", true, 1},
+		{"x3", "", false, 0},
+	}
+	for i, test := range textTests {
+		comment := Commentf("Case %v: %v", i, test)
+		text, parsed, diagnostics := parseText(test.text, 1)
+		c.Check(text.Color, Equals, test.color, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// Outlines.
+	outlineTests := []struct {
+		text   string
+		color  string
+		scale  float64
+		parsed bool
+		errors int
+	}{
+		{"o:navy", "navy", 0, true, 0},
+		{"o:#000000:0.1", "#000000", 0.1, true, 0},
+		{"o", "", 0, true, 1},
+		{"o:beige", "", 0, true, 1},
+		{"o:navy:0", "", 0, true, 1},
+		{"o:navy:2", "", 0, true, 1},
+		{"o:navy:0.1:2", "", 0, true, 1},
+		{"b", "", 0, false, 0},
+	}
+	for i, test := range outlineTests {
+		comment := Commentf("Case %v: %v", i, test)
+		color, scale, parsed, diagnostics := parseOutline(test.text, 1)
+		c.Check(color, Equals, test.color, comment)
+		c.Check(scale, Equals, test.scale, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// Opacity.
+	opacityTests := []struct {
+		text    string
+		opacity float64
+		parsed  bool
+		errors  int
+	}{
+		{"a:0.8", 0.8, true, 0},
+		{"a:1", 1, true, 0},
+		{"a", 0, true, 1},
+		{"a:0", 0, true, 1},
+		{"a:1.5", 0, true, 1},
+		{"a:half", 0, true, 1},
+		{"b", 0, false, 0},
+	}
+	for i, test := range opacityTests {
+		comment := Commentf("Case %v: %v", i, test)
+		opacity, parsed, diagnostics := parseOpacity(test.text, 1)
+		c.Check(opacity, Equals, test.opacity, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// The colors sit alongside the other text settings, in any order.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "I am calm [o:navy:0.1 c:gold:32 a:0.8]\nI am kind [a:0.5 w]")
+	c.Check(diagnostics, IsNil)
+	c.Check(affirmations[0].Text, Equals, TextProperties{Color: "gold", FontSize: 32, OutlineColor: "navy", OutlineScale: 0.1, Opacity: 0.8})
+	c.Check(affirmations[1].Text, Equals, TextProperties{Color: WHITE, Opacity: 0.5})
+}
+
+func (s *AffirmationSuite) Test_ParseEffects(c *C) {
+
+	// Shadows.
+	shadowTests := []struct {
+		text   string
+		shadow Shadow
+		parsed bool
+		errors int
+	}{
+		{"s:black", Shadow{Color: "black", OffsetX: 4, OffsetY: 4, Blur: 4}, true, 0},
+		{"s:#00000080:0,6", Shadow{Color: "#00000080", OffsetX: 0, OffsetY: 6, Blur: 4}, true, 0},
+		{"s:black:0", Shadow{Color: "black", OffsetX: 4, OffsetY: 4, Blur: 0}, true, 0},
+		{"s:black:-2,3:8", Shadow{Color: "black", OffsetX: -2, OffsetY: 3, Blur: 8}, true, 0},
+		{"s", Shadow{}, true, 1},
+		{"s:beige", Shadow{}, true, 1},
+		{"s:black:1,x", Shadow{}, true, 1},
+		{"s:black:-1", Shadow{}, true, 1},
+		{"s:black:1,1:2:3", Shadow{}, true, 1},
+		{"something.jpeg", Shadow{}, false, 0},
+	}
+	for i, test := range shadowTests {
+		comment := Commentf("Case %v: %v", i, test)
+		shadow, parsed, diagnostics := parseShadow(test.text, 1)
+		c.Check(shadow, Equals, test.shadow, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// Glows.
+	glowTests := []struct {
+		text   string
+		glow   Glow
+		parsed bool
+		errors int
+	}{
+		{"g:gold", Glow{Color: "gold", Radius: 8}, true, 0},
+		{"g:gold:12", Glow{Color: "gold", Radius: 12}, true, 0},
+		{"g", Glow{}, true, 1},
+		{"g:gold:0", Glow{}, true, 1},
+		{"g:gold:1:2", Glow{}, true, 1},
+		{"b", Glow{}, false, 0},
+	}
+	for i, test := range glowTests {
+		comment := Commentf("Case %v: %v", i, test)
+		glow, parsed, diagnostics := parseGlow(test.text, 1)
+		c.Check(glow, Equals, test.glow, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// Panels.
+	panelTests := []struct {
+		text   string
+		panel  Panel
+		parsed bool
+		errors int
+	}{
+		{"p:#00000080", Panel{Color: "#00000080", Padding: 16, Radius: 12}, true, 0},
+		{"p:white:20", Panel{Color: "white", Padding: 20, Radius: 12}, true, 0},
+		{"p:white:20:0", Panel{Color: "white", Padding: 20, Radius: 0}, true, 0},
+		{"p", Panel{}, true, 1},
+		{"p:white:x", Panel{}, true, 1},
+		{"p:white:1:2:3", Panel{}, true, 1},
+		{"b", Panel{}, false, 0},
+	}
+	for i, test := range panelTests {
+		comment := Commentf("Case %v: %v", i, test)
+		panel, parsed, diagnostics := parsePanel(test.text, 1)
+		c.Check(panel, Equals, test.panel, comment)
+		c.Check(parsed, Equals, test.parsed, comment)
+		c.Check(len(diagnostics), Equals, test.errors, comment)
+	}
+
+	// The effects sit alongside the other text settings, in any order.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", "I am calm [p:#00000080 w:32 s:black g:gold:4]")
+	c.Check(diagnostics, IsNil)
+	c.Check(affirmations[0].Text, Equals, TextProperties{
+		Color:    WHITE,
+		FontSize: 32,
+		Shadow:   Shadow{Color: "black", OffsetX: 4, OffsetY: 4, Blur: 4},
+		Glow:     Glow{Color: "gold", Radius: 4},
+		Panel:    Panel{Color: "#00000080", Padding: 16, Radius: 12},
+	})
+}
+
+func (s *AffirmationSuite) Test_ParseMarkupDiagnostics(c *C) {
+
+	// Markup mistakes are reported where they are in the file, alongside the display settings.
+	_, _, diagnostics := parseAffirmations("affirmations.txt", "  I am *calm [w:4S]")
+	c.Check(diagnostics, DeepEquals, []ParseDiagnostic{
+		{File: "affirmations.txt", Line: 1, Column: 8, Severity: SEVERITY_WARNING, Message: "unclosed *", Token: "*"},
+		{File: "affirmations.txt", Line: 1, Column: 17, Severity: SEVERITY_ERROR, Message: "invalid font size", Token: "4S"},
+	})
+
+	// An escaped bracket is part of the message.
+	affirmations, _, diagnostics := parseAffirmations("affirmations.txt", `I am \[always\] calm [b]`)
+	c.Check(diagnostics, IsNil)
+	c.Check(affirmations[0].Message, Equals, `I am \[always\] calm`)
+	c.Check(affirmations[0].Text.Color, Equals, BLACK)
+	c.Check(PangoMarkup(affirmations[0].Message), Equals, `<span>I am [always] calm</span>`)
+}
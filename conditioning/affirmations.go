@@ -1,11 +1,14 @@
 package conditioning
 
 import (
+	"fmt"
+	"hash/fnv"
 	"io/ioutil"
-	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 const (
@@ -15,9 +18,57 @@ const (
 
 // Affirmation is a single affirmation.
 type Affirmation struct {
-	Message string           // The text of the affirmation.
-	Image   AffirmationImage // The image associated with the affirmation.
-	Text    TextProperties   // Details about how to display the text.
+	Line     int              // The line of the affirmations file it came from.
+	Message  string           // The text of the affirmation.
+	Image    AffirmationImage // The image associated with the affirmation.
+	Text     TextProperties   // Details about how to display the text.
+	Duration time.Duration    // How long to show the slide in a slide show, 0 for the configured time.
+	Weight   uint             // How many times the slide shows in each cycle of a random slide show, 0 is the same as 1.
+	ID       string           // A name that stays the same when the message is edited, from an "#id" token.
+}
+
+// weight is how many times the affirmation shows in each cycle of a random slide show.
+func (a Affirmation) weight() (weight int) {
+	if a.Weight == 0 {
+		return 1
+	}
+	return int(a.Weight)
+}
+
+// identity is what stays the same about an affirmation across reloads: its id if it has one, otherwise a hash of its message.
+func (a Affirmation) identity() (identity string) {
+	if a.ID != "" {
+		return "#" + a.ID
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(a.Message))
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
+
+// identities gets the identity of each affirmation.
+// A message repeated in the file counts its repeats, so each copy is told apart.
+func identities(affirmations []Affirmation) (identities []string) {
+	repeats := map[string]int{}
+	for _, affirmation := range affirmations {
+		identity := affirmation.identity()
+		if repeats[identity] > 0 {
+			identities = append(identities, identity+"-"+strconv.Itoa(repeats[identity]))
+		} else {
+			identities = append(identities, identity)
+		}
+		repeats[identity]++
+	}
+	return identities
+}
+
+// findIdentity finds the affirmation with an identity.
+func findIdentity(affirmations []Affirmation, identity string) (index int, found bool) {
+	for i, other := range identities(affirmations) {
+		if other == identity {
+			return i, true
+		}
+	}
+	return 0, false
 }
 
 // AffrimationImage is the image details of the affirmation.
@@ -30,20 +81,28 @@ type AffirmationImage struct {
 
 // AffrimationText is the text/font details of the affirmation.
 type TextProperties struct {
-	Black    bool // The color of the text.
-	OffsetX  int  // Offset from center.
-	OffsetY  int  // Offset from center.
-	FontSize uint // The font size of the text.
+	Color        string     // The color of the text, a name or hex ("" for the configured color).
+	OffsetX      int        // Offset from center.
+	OffsetY      int        // Offset from center.
+	FontSize     uint       // The font size of the text.
+	OutlineColor string     // The color of the outline, a name or hex ("" for the configured color).
+	OutlineScale float64    // The 0.0-1.0 % of the font size for the outline (0 for the configured scale).
+	Opacity      float64    // How solid the text is, 0.0-1.0 (0 for the configured opacity).
+	Layout       TextLayout // How the text wraps and fits.
+	Shadow       Shadow     // A soft copy of the text off to the side (no color for the configured shadow).
+	Glow         Glow       // A soft halo around the text (no color for the configured glow).
+	Panel        Panel      // A box behind the text (no color for the configured panel).
 }
 
 // parseAffirmations parses the affirmation text.
-func parseAffirmations(unparsed string) (affirmations []Affirmation, title string) {
+func parseAffirmations(filename, unparsed string) (affirmations []Affirmation, title string, diagnostics []ParseDiagnostic) {
 
 	// Split the text on newlines.
 	lines := strings.Split(unparsed, "\n")
 	parsedNonBlankLine := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	idLines := map[string]int{} // The line each id was first used on.
+	for lineI, rawLine := range lines {
+		line := strings.TrimSpace(rawLine)
 		switch {
 
 		case strings.HasPrefix(line, "//"):
@@ -62,38 +121,165 @@ func parseAffirmations(unparsed string) (affirmations []Affirmation, title strin
 		default:
 			// Not a comment? Not a blank line? This is an affirmation.
 
+			// Where the trimmed line starts in the raw line, for reporting columns.
+			lineColumn := len(rawLine) - len(strings.TrimLeft(rawLine, " \t\r")) + 1
+
 			// Split out the affirmation mesage from the display details.
-			lineParts := strings.Split(line, "[")
+			lineParts := splitDisplay(line)
 			message := strings.TrimSpace(lineParts[0])
 
+			// Check the markup of the message.
+			_, lineDiagnostics := parseMarkup(message, lineColumn)
+
 			// Get the image details.
 			var image AffirmationImage
 			var text TextProperties
+			var duration time.Duration
+			var weight uint
+			var id string
 			if len(lineParts) > 1 {
 
-				// Split the display.
-				displayParts := strings.Split(strings.Trim(lineParts[1], " ]"), " ")
-				for i := 0; i < len(displayParts); i++ {
-					part := displayParts[i]
-
-					// Parse an image.
-					parsedImage, parsed := parseImage(part)
-					if parsed {
-						image = parsedImage
-					}
-
-					// Parse a text display.
-					parsedText, parsed := parseText(part)
-					if parsed {
-						text = parsedText
+				// Split each display group, keeping track of where each part is. Later groups add to earlier ones.
+				displayColumn := lineColumn + len(lineParts[0]) + 1
+				for _, group := range lineParts[1:] {
+					display := strings.TrimRight(group, " ]")
+					for _, part := range strings.Split(display, " ") {
+						partColumn := displayColumn
+						displayColumn += len(part) + 1
+						if part == "" {
+							continue // Extra spaces between parts.
+						}
+
+						// Parse an image.
+						parsedImage, parsed, partDiagnostics := parseImage(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							image = parsedImage
+							continue
+						}
+
+						// Parse a text display.
+						parsedText, parsed, partDiagnostics := parseText(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.Color = parsedText.Color
+							text.OffsetX = parsedText.OffsetX
+							text.OffsetY = parsedText.OffsetY
+							text.FontSize = parsedText.FontSize
+							continue
+						}
+
+						// Parse a text outline.
+						outlineColor, outlineScale, parsed, partDiagnostics := parseOutline(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.OutlineColor = outlineColor
+							text.OutlineScale = outlineScale
+							continue
+						}
+
+						// Parse a text opacity.
+						opacity, parsed, partDiagnostics := parseOpacity(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.Opacity = opacity
+							continue
+						}
+
+						// Parse a text shadow.
+						shadow, parsed, partDiagnostics := parseShadow(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.Shadow = shadow
+							continue
+						}
+
+						// Parse a text glow.
+						glow, parsed, partDiagnostics := parseGlow(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.Glow = glow
+							continue
+						}
+
+						// Parse a text panel.
+						panel, parsed, partDiagnostics := parsePanel(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.Panel = panel
+							continue
+						}
+
+						// Parse how the text wraps.
+						parsedLayout, parsed, partDiagnostics := parseWrap(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							text.Layout = parsedLayout
+							continue
+						}
+
+						// Parse a display duration.
+						parsedDuration, parsed, partDiagnostics := parseDuration(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							duration = parsedDuration
+							continue
+						}
+
+						// Parse a random slide show weight.
+						parsedWeight, parsed, partDiagnostics := parseWeight(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							weight = parsedWeight
+							continue
+						}
+
+						// Parse an id.
+						parsedID, parsed, partDiagnostics := parseID(part, partColumn)
+						lineDiagnostics = append(lineDiagnostics, partDiagnostics...)
+						if parsed {
+							if firstLine, found := idLines[parsedID]; found {
+								// Two affirmations can't be the same one.
+								lineDiagnostics = append(lineDiagnostics, ParseDiagnostic{
+									Column:   partColumn,
+									Severity: SEVERITY_ERROR,
+									Message:  "duplicate id, first on line " + strconv.Itoa(firstLine),
+									Token:    part,
+								})
+							} else if parsedID != "" {
+								idLines[parsedID] = lineI + 1
+								id = parsedID
+							}
+							continue
+						}
+
+						// Nothing understood this part.
+						lineDiagnostics = append(lineDiagnostics, ParseDiagnostic{
+							Column:   partColumn,
+							Severity: SEVERITY_WARNING,
+							Message:  "unknown display token",
+							Token:    part,
+						})
 					}
+					displayColumn += len(group) - len(display) // Past the end of the group and the next "[".
 				}
 			}
 
+			// Place the problems in the file.
+			for _, diagnostic := range lineDiagnostics {
+				diagnostic.File = filename
+				diagnostic.Line = lineI + 1
+				diagnostics = append(diagnostics, diagnostic)
+			}
+
 			affirmations = append(affirmations, Affirmation{
-				Message: message,
-				Image:   image,
-				Text:    text,
+				Line:     lineI + 1,
+				Message:  message,
+				Image:    image,
+				Text:     text,
+				Duration: duration,
+				Weight:   weight,
+				ID:       id,
 			})
 
 			// We have parsed a line.
@@ -101,55 +287,68 @@ func parseAffirmations(unparsed string) (affirmations []Affirmation, title strin
 		}
 	}
 
-	return affirmations, title
+	return affirmations, title, diagnostics
 }
 
-// parseImage parses the part of an affirmation that
-func parseImage(text string) (image AffirmationImage, parsed bool) {
+// splitDisplay splits an affirmation line on each "[" that isn't escaped in the message.
+func splitDisplay(line string) (lineParts []string) {
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case _MARKUP_ESCAPE:
+			i++ // Skip what is escaped.
+		case '[':
+			lineParts = append(lineParts, line[start:i])
+			start = i + 1
+		}
+	}
+	return append(lineParts, line[start:])
+}
+
+// parseImage parses the part of an affirmation that describes the image.
+func parseImage(text string, column int) (image AffirmationImage, parsed bool, diagnostics []ParseDiagnostic) {
 	textParts := strings.Split(text, ":")
 
-	// Is there a file extension?
+	// Is there a file extension? An id like "#my.id" or a weight like "x1.5" has a "." too, but isn't a file.
 	filename := textParts[0]
-	if strings.Index(filename, ".") == -1 {
-		return AffirmationImage{}, false // Not a filename.
+	if strings.Index(filename, ".") == -1 || strings.HasPrefix(filename, "#") || looksLikeWeight(filename) {
+		return AffirmationImage{}, false, nil // Not a filename.
 	}
 
 	// Examine each other part of the display.
 	var scale float64 = 1.0
 	var offsetX, offsetY int
+	partColumn := column + len(filename) + 1
 	for i := 1; i < len(textParts); i++ {
 		part := textParts[i]
 		switch {
 
 		// Is this coordinates?
 		case strings.Index(part, ",") >= 0:
-			coordinateParts := strings.Split(part, ",")
-			x, xErr := strconv.Atoi(coordinateParts[0])
-			y, yErr := strconv.Atoi(coordinateParts[1])
-			if xErr == nil && yErr == nil {
+			x, y, diagnostic, ok := parseOffset(part, partColumn)
+			if ok {
 				offsetX = x
 				offsetY = y
 			} else {
-				if xErr != nil {
-					log.Printf("%+v\n", xErr)
-				}
-				if yErr != nil {
-					log.Printf("%+v\n", yErr)
-				}
+				diagnostics = append(diagnostics, diagnostic)
 			}
 
 			// Attempt to parse a scale.
 		default:
 
 			value, err := strconv.ParseFloat(part, 64)
-			if err == nil {
+			if err == nil && value > 0 {
 				scale = value
 			} else {
-				if err != nil {
-					log.Printf("%+v\n", err)
-				}
+				diagnostics = append(diagnostics, ParseDiagnostic{
+					Column:   partColumn,
+					Severity: SEVERITY_ERROR,
+					Message:  "invalid image scale",
+					Token:    part,
+				})
 			}
 		}
+		partColumn += len(part) + 1
 	}
 
 	return AffirmationImage{
@@ -157,91 +356,432 @@ func parseImage(text string) (image AffirmationImage, parsed bool) {
 		OffsetX:  offsetX,
 		OffsetY:  offsetY,
 		Scale:    scale,
-	}, true
+	}, true, diagnostics
 }
 
-// parseText parses the part of an affirmation that
-func parseText(text string) (textDetails TextProperties, parsed bool) {
+// parseText parses the part of an affirmation that describes the text.
+func parseText(text string, column int) (textDetails TextProperties, parsed bool, diagnostics []ParseDiagnostic) {
 	textParts := strings.Split(text, ":")
 
-	// At the beginning, we need to know if this is black or white text.
-	color := textParts[0]
-	var black bool
-	switch color {
+	// At the beginning, we need to know the color of the text.
+	var color string
+	colorParts := 1 // How many parts give the color.
+	switch textParts[0] {
 	case "b":
-		black = true
+		color = BLACK
 	case "w":
-		black = false
+		color = WHITE
+	case "c":
+		// The color follows, like "c:gold", "c:#ffcc00" or "c:auto".
+		if len(textParts) < 2 || !validTextColor(textParts[1]) {
+			return TextProperties{}, true, []ParseDiagnostic{{
+				Column:   column,
+				Severity: SEVERITY_ERROR,
+				Message:  "invalid color, expected c:name or c:#rrggbb",
+				Token:    text,
+			}}
+		}
+		color = textParts[1]
+		colorParts = 2
 	default:
-		return TextProperties{}, false // Not a color.
+		return TextProperties{}, false, nil // Not a color.
 	}
 
 	// Examine each other part of the display.
 	var fontSize, offsetX, offsetY int
-	for i := 1; i < len(textParts); i++ {
+	partColumn := column + len(strings.Join(textParts[:colorParts], ":")) + 1
+	for i := colorParts; i < len(textParts); i++ {
 		part := textParts[i]
 		switch {
 
 		// Is this coordinates?
 		case strings.Index(part, ",") >= 0:
-			coordinateParts := strings.Split(part, ",")
-			x, xErr := strconv.Atoi(coordinateParts[0])
-			y, yErr := strconv.Atoi(coordinateParts[1])
-			if xErr == nil && yErr == nil {
+			x, y, diagnostic, ok := parseOffset(part, partColumn)
+			if ok {
 				offsetX = x
 				offsetY = y
 			} else {
-				if xErr != nil {
-					log.Printf("%+v\n", xErr)
-				}
-				if yErr != nil {
-					log.Printf("%+v\n", yErr)
-				}
+				diagnostics = append(diagnostics, diagnostic)
 			}
 
 			// Attempt to parse a font size.
 		default:
 
 			value, err := strconv.Atoi(part)
-			if err == nil {
+			if err == nil && value > 0 {
 				fontSize = value
 			} else {
-				if err != nil {
-					log.Printf("%+v\n", err)
-				}
+				diagnostics = append(diagnostics, ParseDiagnostic{
+					Column:   partColumn,
+					Severity: SEVERITY_ERROR,
+					Message:  "invalid font size",
+					Token:    part,
+				})
 			}
 		}
+		partColumn += len(part) + 1
 	}
 
 	return TextProperties{
-		Black:    black,
+		Color:    color,
 		OffsetX:  offsetX,
 		OffsetY:  offsetY,
 		FontSize: uint(fontSize),
-	}, true
+	}, true, diagnostics
+}
+
+// parseOutline parses the part of an affirmation that describes the text outline, like "o:navy" or "o:#000000:0.1".
+func parseOutline(text string, column int) (color string, scale float64, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is an outline.
+	if textParts[0] != "o" {
+		return "", 0, false, nil // Not an outline.
+	}
+
+	// The color, then optionally the 0.0-1.0 % of the font size for the outline.
+	valid := len(textParts) == 2 || len(textParts) == 3
+	if valid {
+		color = textParts[1]
+		valid = validColor(color)
+	}
+	if valid && len(textParts) == 3 {
+		var err error
+		scale, err = strconv.ParseFloat(textParts[2], 64)
+		valid = err == nil && scale > 0 && scale <= 1
+	}
+	if !valid {
+		return "", 0, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid outline, expected o:color or o:color:0.1",
+			Token:    text,
+		}}
+	}
+
+	return color, scale, true, nil
+}
+
+// parseOpacity parses the part of an affirmation that says how solid the text is, like "a:0.8".
+func parseOpacity(text string, column int) (opacity float64, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is an opacity.
+	if textParts[0] != "a" {
+		return 0, false, nil // Not an opacity.
+	}
+
+	// The opacity must be more than 0.0 and at most 1.0.
+	var err error
+	if len(textParts) == 2 {
+		opacity, err = strconv.ParseFloat(textParts[1], 64)
+	}
+	if len(textParts) != 2 || err != nil || opacity <= 0 || opacity > 1 {
+		return 0, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid opacity, expected a:0.8",
+			Token:    text,
+		}}
+	}
+
+	return opacity, true, nil
+}
+
+// parseShadow parses the part of an affirmation that describes a shadow behind the text, like "s:black" or "s:#00000080:4,4:6".
+func parseShadow(text string, column int) (shadow Shadow, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is a shadow.
+	if textParts[0] != "s" {
+		return Shadow{}, false, nil // Not a shadow.
+	}
+
+	// The color, then optionally the offset and the blur.
+	shadow = Shadow{OffsetX: _DEFAULT_SHADOW_OFFSET, OffsetY: _DEFAULT_SHADOW_OFFSET, Blur: _DEFAULT_SHADOW_BLUR}
+	valid := len(textParts) >= 2 && len(textParts) <= 4 && validColor(textParts[1])
+	for i := 2; valid && i < len(textParts); i++ {
+		part := textParts[i]
+		if strings.Index(part, ",") >= 0 {
+			var ok bool
+			shadow.OffsetX, shadow.OffsetY, _, ok = parseOffset(part, column)
+			valid = ok
+		} else {
+			blur, err := strconv.Atoi(part)
+			shadow.Blur = uint(blur)
+			valid = err == nil && blur >= 0
+		}
+	}
+	if !valid {
+		return Shadow{}, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid shadow, expected s:color[:x,y][:blur]",
+			Token:    text,
+		}}
+	}
+	shadow.Color = textParts[1]
+
+	return shadow, true, nil
+}
+
+// parseGlow parses the part of an affirmation that describes a glow around the text, like "g:gold" or "g:gold:12".
+func parseGlow(text string, column int) (glow Glow, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is a glow.
+	if textParts[0] != "g" {
+		return Glow{}, false, nil // Not a glow.
+	}
+
+	// The color, then optionally the radius.
+	glow = Glow{Radius: _DEFAULT_GLOW_RADIUS}
+	valid := (len(textParts) == 2 || len(textParts) == 3) && validColor(textParts[1])
+	if valid && len(textParts) == 3 {
+		radius, err := strconv.Atoi(textParts[2])
+		glow.Radius = uint(radius)
+		valid = err == nil && radius > 0
+	}
+	if !valid {
+		return Glow{}, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid glow, expected g:color[:radius]",
+			Token:    text,
+		}}
+	}
+	glow.Color = textParts[1]
+
+	return glow, true, nil
+}
+
+// parsePanel parses the part of an affirmation that describes a box behind the text, like "p:#00000080" or "p:#00000080:20:8".
+func parsePanel(text string, column int) (panel Panel, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is a panel.
+	if textParts[0] != "p" {
+		return Panel{}, false, nil // Not a panel.
+	}
+
+	// The color, then optionally the padding and then the corner radius.
+	panel = Panel{Padding: _DEFAULT_PANEL_PADDING, Radius: _DEFAULT_PANEL_RADIUS}
+	valid := len(textParts) >= 2 && len(textParts) <= 4 && validColor(textParts[1])
+	sizes := []*uint{&panel.Padding, &panel.Radius}
+	for i := 2; valid && i < len(textParts); i++ {
+		size, err := strconv.Atoi(textParts[i])
+		*sizes[i-2] = uint(size)
+		valid = err == nil && size >= 0
+	}
+	if !valid {
+		return Panel{}, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid panel, expected p:color[:padding[:radius]]",
+			Token:    text,
+		}}
+	}
+	panel.Color = textParts[1]
+
+	return panel, true, nil
+}
+
+// parseWrap parses the part of an affirmation that says how the text wraps, like "wrap:800x300:left:fit".
+func parseWrap(text string, column int) (textLayout TextLayout, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is wrapping.
+	if textParts[0] != "wrap" {
+		return TextLayout{}, false, nil // Not wrapping.
+	}
+
+	// The width, and optionally the height, must be positive whole numbers.
+	invalid := func(part string, partColumn int) {
+		diagnostics = append(diagnostics, ParseDiagnostic{
+			Column:   partColumn,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid wrap, expected wrap:width[xheight][:left|center|right|justify][:fit|:nofit]",
+			Token:    part,
+		})
+	}
+	partColumn := column + len(textParts[0]) + 1
+	if len(textParts) < 2 {
+		invalid(text, column)
+		return TextLayout{}, true, diagnostics
+	}
+	sizeParts := strings.Split(textParts[1], "x")
+	width, err := strconv.Atoi(sizeParts[0])
+	valid := len(sizeParts) <= 2 && err == nil && width > 0
+	height := 0
+	if len(sizeParts) == 2 {
+		height, err = strconv.Atoi(sizeParts[1])
+		valid = valid && err == nil && height > 0
+	}
+	if !valid {
+		invalid(textParts[1], partColumn)
+		return TextLayout{}, true, diagnostics
+	}
+	textLayout.MaxWidth = uint(width)
+	textLayout.MaxHeight = uint(height)
+
+	// Examine each other part of the wrapping.
+	partColumn += len(textParts[1]) + 1
+	for i := 2; i < len(textParts); i++ {
+		part := textParts[i]
+		switch align := TextAlign(part); {
+		case part == "fit":
+			textLayout.ShrinkToFit, textLayout.NoShrink = true, false
+		case part == "nofit":
+			textLayout.ShrinkToFit, textLayout.NoShrink = false, true
+		case part != "" && validTextAlign(align):
+			textLayout.Align = align
+		default:
+			invalid(part, partColumn)
+		}
+		partColumn += len(part) + 1
+	}
+
+	// The height only matters when shrinking to fit.
+	if height != 0 && !textLayout.ShrinkToFit {
+		diagnostics = append(diagnostics, ParseDiagnostic{
+			Column:   column + len(textParts[0]) + 1,
+			Severity: SEVERITY_WARNING,
+			Message:  "wrap height is only used when shrinking to fit, add :fit",
+			Token:    textParts[1],
+		})
+	}
+
+	return textLayout, true, diagnostics
+}
+
+// parseDuration parses the part of an affirmation that says how long to display it, like "t:8s".
+func parseDuration(text string, column int) (duration time.Duration, parsed bool, diagnostics []ParseDiagnostic) {
+	textParts := strings.Split(text, ":")
+
+	// At the beginning, we need to know if this is a time.
+	if textParts[0] != "t" {
+		return 0, false, nil // Not a time.
+	}
+
+	// The time must be a positive duration like "8s" or "1500ms".
+	var err error
+	if len(textParts) == 2 {
+		duration, err = time.ParseDuration(textParts[1])
+	}
+	if len(textParts) != 2 || err != nil || duration <= 0 {
+		return 0, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid display duration, expected t:8s",
+			Token:    text,
+		}}
+	}
+
+	return duration, true, nil
+}
+
+// parseWeight parses the part of an affirmation that says how often it shows in a random slide show, like "x3".
+func parseWeight(text string, column int) (weight uint, parsed bool, diagnostics []ParseDiagnostic) {
+
+	// At the beginning, we need to know if this is a weight.
+	if !strings.HasPrefix(text, "x") {
+		return 0, false, nil // Not a weight.
+	}
+
+	// The weight must be a positive whole number.
+	value, err := strconv.Atoi(text[1:])
+	if err != nil || value <= 0 {
+		return 0, true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid weight, expected x3",
+			Token:    text,
+		}}
+	}
+
+	return uint(value), true, nil
+}
+
+// looksLikeWeight is true for a weight, even one that isn't a whole number like "x1.5".
+func looksLikeWeight(text string) (weight bool) {
+	if !strings.HasPrefix(text, "x") {
+		return false
+	}
+	_, err := strconv.ParseFloat(text[1:], 64)
+	return err == nil
+}
+
+// parseID parses the part of an affirmation that names it, like "#calm".
+func parseID(text string, column int) (id string, parsed bool, diagnostics []ParseDiagnostic) {
+
+	// At the beginning, we need to know if this is an id.
+	if !strings.HasPrefix(text, "#") {
+		return "", false, nil // Not an id.
+	}
+
+	// The id must be letters, digits, dashes and underscores.
+	id = text[1:]
+	valid := id != ""
+	for _, r := range id {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			valid = false
+		}
+	}
+	if !valid {
+		return "", true, []ParseDiagnostic{{
+			Column:   column,
+			Severity: SEVERITY_ERROR,
+			Message:  "invalid id, expected #name",
+			Token:    text,
+		}}
+	}
+
+	return id, true, nil
+}
+
+// parseOffset parses an "x,y" offset from center.
+func parseOffset(text string, column int) (x, y int, diagnostic ParseDiagnostic, ok bool) {
+	invalid := ParseDiagnostic{
+		Column:   column,
+		Severity: SEVERITY_ERROR,
+		Message:  "invalid offset, expected x,y",
+		Token:    text,
+	}
+
+	coordinateParts := strings.Split(text, ",")
+	if len(coordinateParts) != 2 {
+		return 0, 0, invalid, false
+	}
+	x, xErr := strconv.Atoi(coordinateParts[0])
+	y, yErr := strconv.Atoi(coordinateParts[1])
+	if xErr != nil || yErr != nil {
+		return 0, 0, invalid, false
+	}
+
+	return x, y, ParseDiagnostic{}, true
 }
 
-// LoadAffirmations loads the affirmations from the affirmations file.
-func LoadAffirmations(affirmationFilename string) (affirmations []Affirmation, title string, err error) {
+// LoadAffirmations loads the affirmations from the affirmations file, along with any problems found parsing it.
+func LoadAffirmations(affirmationFilename string) (affirmations []Affirmation, title string, diagnostics []ParseDiagnostic, err error) {
 
 	// Open the file.
 	file, err := os.Open(affirmationFilename)
 	if err != nil {
-		return nil, "", Error(err)
+		return nil, "", nil, Error(err)
 	}
 	defer file.Close()
 
 	// Load the bytes from the file.
 	bytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		return nil, "", Error(err)
+		return nil, "", nil, Error(err)
 	}
 
 	// Extract the affirmations.
-	affirmations, title = parseAffirmations(string(bytes))
+	affirmations, title, diagnostics = parseAffirmations(affirmationFilename, string(bytes))
 	if title == "" {
 		title = _DEFAULT_TITLE
 	}
 
-	return affirmations, title, nil
+	return affirmations, title, diagnostics, nil
 }
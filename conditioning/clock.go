@@ -0,0 +1,148 @@
+package conditioning
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes timers, so slide show timing can be simulated.
+type Clock interface {
+	Now() (now time.Time)                          // The current time.
+	NewTimer(duration time.Duration) (timer Timer) // A timer that fires once after the duration.
+}
+
+// Timer fires once on its channel, like a time.Timer.
+type Timer interface {
+	C() (c <-chan time.Time)                       // The channel the time is sent on when the timer fires.
+	Stop() (stopped bool)                          // Stop the timer, false if it had already fired or stopped.
+	Reset(duration time.Duration) (wasActive bool) // Fire after the duration from now instead.
+}
+
+// NewRealClock creates a clock that uses the real time.
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+// realClock uses the real time.
+type realClock struct{}
+
+// Now is the current time.
+func (realClock) Now() (now time.Time) {
+	return time.Now()
+}
+
+// NewTimer makes a real timer.
+func (realClock) NewTimer(duration time.Duration) (timer Timer) {
+	return realTimer{time.NewTimer(duration)}
+}
+
+// realTimer is a time.Timer.
+type realTimer struct {
+	*time.Timer
+}
+
+// C is the channel the time is sent on when the timer fires.
+func (t realTimer) C() (c <-chan time.Time) {
+	return t.Timer.C
+}
+
+// ManualClock is a clock whose time only moves when told to, for testing.
+type ManualClock struct {
+	mux    *sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock creates a clock stopped at a time.
+func NewManualClock(now time.Time) (clock *ManualClock) {
+	return &ManualClock{
+		mux: &sync.Mutex{},
+		now: now,
+	}
+}
+
+// Now is the clock's current time.
+func (m *ManualClock) Now() (now time.Time) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.now
+}
+
+// NewTimer makes a timer that fires when the clock is advanced past the duration.
+func (m *ManualClock) NewTimer(duration time.Duration) (timer Timer) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	manual := &manualTimer{
+		clock:    m,
+		c:        make(chan time.Time, 1),
+		deadline: m.now.Add(duration),
+		active:   true,
+	}
+	m.timers = append(m.timers, manual)
+	return manual
+}
+
+// Advance moves the clock forward, firing every timer that comes due, soonest first.
+func (m *ManualClock) Advance(duration time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.now = m.now.Add(duration)
+
+	// Which timers are due?
+	var due []*manualTimer
+	for _, timer := range m.timers {
+		if timer.active && !timer.deadline.After(m.now) {
+			due = append(due, timer)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return due[a].deadline.Before(due[b].deadline)
+	})
+
+	// Fire them.
+	for _, timer := range due {
+		timer.active = false
+		select {
+		case timer.c <- m.now:
+		default: // Already holding a time nobody read, like a time.Timer.
+		}
+	}
+}
+
+// manualTimer is a timer of a manual clock.
+type manualTimer struct {
+	clock    *ManualClock
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+// C is the channel the time is sent on when the timer fires.
+func (t *manualTimer) C() (c <-chan time.Time) {
+	return t.c
+}
+
+// Stop stops the timer, false if it had already fired or stopped.
+func (t *manualTimer) Stop() (stopped bool) {
+	t.clock.mux.Lock()
+	defer t.clock.mux.Unlock()
+
+	stopped = t.active
+	t.active = false
+	return stopped
+}
+
+// Reset makes the timer fire after the duration from the clock's current time.
+func (t *manualTimer) Reset(duration time.Duration) (wasActive bool) {
+	t.clock.mux.Lock()
+	defer t.clock.mux.Unlock()
+
+	wasActive = t.active
+	t.deadline = t.clock.now.Add(duration)
+	t.active = true
+	return wasActive
+}
@@ -0,0 +1,78 @@
+package conditioning
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type ClockSuite struct{}
+
+var _ = Suite(&ClockSuite{})
+
+// Add the tests.
+
+// fired checks whether a timer has fired without waiting.
+func fired(timer Timer) (at time.Time, ok bool) {
+	select {
+	case at = <-timer.C():
+		return at, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func (s *ClockSuite) Test_ManualClock(c *C) {
+	start := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	c.Check(clock.Now(), Equals, start)
+
+	// Not due yet.
+	timer := clock.NewTimer(time.Second)
+	clock.Advance(999 * time.Millisecond)
+	_, ok := fired(timer)
+	c.Check(ok, Equals, false)
+
+	// Due.
+	clock.Advance(time.Millisecond)
+	at, ok := fired(timer)
+	c.Check(ok, Equals, true)
+	c.Check(at, Equals, start.Add(time.Second))
+	c.Check(clock.Now(), Equals, start.Add(time.Second))
+
+	// Fires only once.
+	clock.Advance(time.Hour)
+	_, ok = fired(timer)
+	c.Check(ok, Equals, false)
+	c.Check(timer.Stop(), Equals, false)
+
+	// Reset counts from now.
+	c.Check(timer.Reset(time.Minute), Equals, false)
+	clock.Advance(59 * time.Second)
+	_, ok = fired(timer)
+	c.Check(ok, Equals, false)
+	clock.Advance(time.Second)
+	_, ok = fired(timer)
+	c.Check(ok, Equals, true)
+
+	// Stopped timers never fire.
+	timer = clock.NewTimer(time.Second)
+	c.Check(timer.Stop(), Equals, true)
+	clock.Advance(time.Hour)
+	_, ok = fired(timer)
+	c.Check(ok, Equals, false)
+}
+
+func (s *ClockSuite) Test_RealClock(c *C) {
+	clock := NewRealClock()
+	before := time.Now()
+	c.Check(clock.Now().Before(before), Equals, false)
+
+	timer := clock.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(5 * time.Second):
+		c.Fatal("real timer never fired")
+	}
+}
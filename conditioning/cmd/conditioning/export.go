@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"time"
+
+	"glemzurg/conditioning"
+	"glemzurg/conditioning/display"
+)
+
+// export renders a slide show to files without opening a window.
+func export(args []string) {
+
+	var configFilename, affirmationFilename, outputPath, format string
+	var width, height int
+	var fps float64
+	var random bool
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.StringVar(&configFilename, "config", "", "configuration")
+	flags.StringVar(&affirmationFilename, "affirm", "", "affirmations")
+	flags.StringVar(&outputPath, "out", "", "output path for png and frames, output file for pdf and gif (default is the current path, slides.pdf, or slides.gif)")
+	flags.StringVar(&format, "format", "png", "output format: png, pdf, gif, frames")
+	flags.Float64Var(&fps, "fps", 30, "frames per second for frames")
+	flags.BoolVar(&random, "random", false, "random slide show order for gif and frames")
+	flags.IntVar(&width, "width", 0, "output width (default is the configured screen width)")
+	flags.IntVar(&height, "height", 0, "output height (default is the configured screen height)")
+	flags.Parse(args)
+
+	// Get the config in a useable form.
+	config, err := conditioning.LoadConfig(configFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	imagePath := imagePathFor(affirmationFilename)
+
+	// Random seed.
+	rand.Seed(time.Now().UnixNano())
+
+	switch format {
+
+	case "png":
+		if outputPath == "" {
+			outputPath = "."
+		}
+		filenames, err := display.ExportPNGs(config, affirmationFilename, imagePath, outputPath, width, height)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, filename := range filenames {
+			log.Println(`wrote: `, filename)
+		}
+
+	case "pdf":
+		if outputPath == "" {
+			outputPath = "slides.pdf"
+		}
+		if err = display.ExportPDF(config, affirmationFilename, imagePath, outputPath, width, height); err != nil {
+			log.Fatal(err)
+		}
+		log.Println(`wrote: `, outputPath)
+
+	case "gif":
+		if outputPath == "" {
+			outputPath = "slides.gif"
+		}
+		if err = display.ExportGIF(config, affirmationFilename, imagePath, outputPath, width, height, random); err != nil {
+			log.Fatal(err)
+		}
+		log.Println(`wrote: `, outputPath)
+
+	case "frames":
+		if outputPath == "" {
+			outputPath = "."
+		}
+		filenames, err := display.ExportFrames(config, affirmationFilename, imagePath, outputPath, width, height, fps, random)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote: %d frames to %s", len(filenames), outputPath)
+
+	default:
+		log.Fatalf(`unknown export format: '%s'`, format)
+	}
+}
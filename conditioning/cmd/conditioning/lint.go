@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"glemzurg/conditioning"
+	"glemzurg/conditioning/display"
+)
+
+// lint checks a slide show for problems without opening a window.
+func lint(args []string) {
+
+	var configFilename, affirmationFilename string
+	var strict bool
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	flags.StringVar(&configFilename, "config", "", "configuration")
+	flags.StringVar(&affirmationFilename, "affirm", "", "affirmations")
+	flags.BoolVar(&strict, "strict", false, "fail on warnings too")
+	flags.Parse(args)
+
+	// Get the config in a useable form.
+	config, err := conditioning.LoadConfig(configFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Check everything.
+	diagnostics, err := conditioning.LintAffirmations(display.NewSlidePreparer(), config, affirmationFilename, imagePathFor(affirmationFilename))
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := false
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic)
+		if strict || diagnostic.Severity == conditioning.SEVERITY_ERROR {
+			failed = true
+		}
+	}
+
+	// An error (or in strict mode, any problem) should stop a pre-commit hook.
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"log"
 	"math/rand"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -12,32 +13,45 @@ import (
 	"github.com/gotk3/gotk3/gtk"
 
 	"glemzurg/conditioning"
-)
-
-const (
-	// Key strokes used.
-	_KEY_SPACE      = 32
-	_KEY_L          = 108
-	_KEY_R          = 114
-	_KEY_LEFT  uint = 65361
-	// _KEY_UP    uint = 65362
-	_KEY_RIGHT uint = 65363
-	// _KEY_DOWN  uint = 65364
+	"glemzurg/conditioning/display"
 )
 
 func main() {
 	var err error
 
+	// Is this a subcommand?
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			lint(os.Args[2:])
+			return
+		case "export":
+			export(os.Args[2:])
+			return
+		}
+	}
+
 	var configFilename, affirmationFilename string
+	var fullscreen, kiosk, autoStart, fresh bool
+	var cycles uint
+	var duration time.Duration
+	var endAction string
 	flag.StringVar(&configFilename, "config", "", "configuration")
 	flag.StringVar(&affirmationFilename, "affirm", "", "affirmations")
+	flag.BoolVar(&fullscreen, "fullscreen", false, "fill the monitor")
+	flag.BoolVar(&kiosk, "kiosk", false, "run unattended: fullscreen, no cursor, slide show playing, no keys that change what is shown")
+	flag.BoolVar(&autoStart, "autostart", false, "start the slide show on launch")
+	flag.BoolVar(&fresh, "fresh", false, "start fresh instead of where the last session left off")
+	flag.UintVar(&cycles, "cycles", 0, "end the slide show after this many full passes")
+	flag.DurationVar(&duration, "duration", 0, "end the slide show after playing this long, like 20m")
+	flag.StringVar(&endAction, "end", "", "when the slide show ends: quit, closing, or black (default stay on the last slide)")
 	flag.Parse()
 
 	log.Println(`config: `, configFilename)
 	log.Println(`affirmations: `, affirmationFilename)
 
 	// The image path is the root of the affirmations.
-	imagePath := filepath.Dir(affirmationFilename) + "/images/"
+	imagePath := imagePathFor(affirmationFilename)
 	log.Println(`images: `, imagePath)
 
 	// Get the config in a useable form.
@@ -45,18 +59,43 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// The flags turn on what the config may not have.
+	config.Fullscreen = config.Fullscreen || fullscreen || kiosk || config.Kiosk
+	config.Kiosk = config.Kiosk || kiosk
+	config.AutoStart = config.AutoStart || autoStart || config.Kiosk
+	if cycles > 0 {
+		config.StopAfterCycles = cycles
+	}
+	if duration > 0 {
+		config.StopAfterMilli = uint(duration / time.Millisecond)
+	}
+	if endAction != "" {
+		config.EndAction = conditioning.EndAction(endAction)
+	}
 	log.Printf("%+v\n", config)
 
 	// Random seed.
 	rand.Seed(time.Now().UnixNano())
 
-	// Prime the system.
-	system, err := conditioning.NewSystem(config, affirmationFilename, imagePath)
+	// Prime the system, redrawing the window whenever it changes.
+	cache := newSlideCache()
+	presenter := &gtkPresenter{cache: cache, endAction: config.EndAction}
+	system, err := conditioning.NewSystem(config, affirmationFilename, imagePath, conditioning.WithPresenter(presenter), conditioning.WithSlidePreparer(display.NewSlidePreparer()))
 	if err != nil {
 		log.Fatal(err)
 	}
+	presenter.system = system
+
+	// The keys, by key value.
+	keyBindings := conditioning.KeyBindings(config)
+	actions := map[uint]conditioning.Action{}
+	for keyName, action := range keyBindings {
+		keyValue, _ := conditioning.KeyValue(keyName) // Validated with the config.
+		actions[keyValue] = action
+	}
 
-	log.Printf("\n\nCommands are LEFT+RIGHT ARROWS (go back and forth in slide show), SPACE (start/stop slide show), R (toggle order of slideshow to random), L (reload from file)\n\n")
+	log.Printf("\n\nCommands are %s\n\n", conditioning.DescribeKeyBindings(keyBindings))
 
 	// Load the affiramtions.
 	title, err := system.Load()
@@ -64,6 +103,13 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Carry on where the last session left off.
+	if !fresh {
+		if err = system.RestoreState(); err != nil {
+			log.Printf(`RestoreState(): %+v`, err)
+		}
+	}
+
 	// Initialize GTK without parsing any command line arguments.
 	gtk.Init(nil)
 
@@ -74,6 +120,7 @@ func main() {
 		log.Fatal("Unable to create window:", err)
 	}
 
+	presenter.win = win
 	win.SetTitle(title)
 	win.Connect("destroy", func() {
 		gtk.MainQuit()
@@ -89,38 +136,78 @@ func main() {
 
 	// Add a key press event.
 	win.Connect("key-press-event", func(win *gtk.Window, ev *gdk.Event) {
-		keyEvent := &gdk.EventKey{ev}
+		keyEvent := &gdk.EventKey{Event: ev}
 
-		switch keyEvent.KeyVal() {
+		switch actions[keyEvent.KeyVal()] {
 
-		case _KEY_SPACE:
-			if err = system.StartStopSlideShow(win); err != nil {
-				log.Printf(`key-press-event StartStopSlideShow(): %+v`, err)
+		case conditioning.ACTION_TOGGLE_PLAY:
+			if system.IsPlaying() {
+				if err = system.Pause(); err != nil {
+					log.Printf(`key-press-event Pause(): %+v`, err)
+				}
+			} else {
+				if err = system.Resume(); err != nil {
+					log.Printf(`key-press-event Resume(): %+v`, err)
+				}
 			}
 
-		case _KEY_L:
-			title, err = system.Load()
-			if err != nil {
+		case conditioning.ACTION_STOP:
+			if system.IsPlaying() || system.IsPaused() {
+				if err = system.StartStopSlideShow(); err != nil {
+					log.Printf(`key-press-event StartStopSlideShow(): %+v`, err)
+				}
+			}
+
+		case conditioning.ACTION_SPEED_UP:
+			if err = system.SpeedUp(); err != nil {
+				log.Printf(`key-press-event SpeedUp(): %+v`, err)
+			}
+
+		case conditioning.ACTION_SLOW_DOWN:
+			if err = system.SlowDown(); err != nil {
+				log.Printf(`key-press-event SlowDown(): %+v`, err)
+			}
+
+		case conditioning.ACTION_SAVE_SPEED:
+			if err = system.SaveSpeed(configFilename); err != nil {
+				log.Printf(`key-press-event SaveSpeed(): %+v`, err)
+			}
+
+		case conditioning.ACTION_RELOAD:
+			if _, err = system.Load(); err != nil {
 				log.Printf(`key-press-event Load(): %+v`, err)
 			}
-			win.SetTitle(title)
-			win.QueueDraw()
 
-		case _KEY_R:
+		case conditioning.ACTION_TOGGLE_RANDOM:
 			system.RandomOnOff()
-			win.QueueDraw()
 
-		case _KEY_LEFT:
+		case conditioning.ACTION_TOGGLE_SPACED:
+			if err = system.SpacedOnOff(); err != nil {
+				log.Printf(`key-press-event SpacedOnOff(): %+v`, err)
+			}
+
+		case conditioning.ACTION_KNOWN, conditioning.ACTION_NOT_YET:
+			if err = system.Grade(actions[keyEvent.KeyVal()] == conditioning.ACTION_KNOWN); err != nil {
+				log.Printf(`key-press-event Grade(): %+v`, err)
+			}
+
+		case conditioning.ACTION_TOGGLE_FULLSCREEN:
+			config.Fullscreen = !config.Fullscreen
+			if config.Fullscreen {
+				win.Fullscreen()
+			} else {
+				win.Unfullscreen()
+			}
+
+		case conditioning.ACTION_PREV:
 			if err = system.Left(); err != nil {
 				log.Printf(`key-press-event Left(): %+v`, err)
 			}
-			win.QueueDraw()
 
-		case _KEY_RIGHT:
+		case conditioning.ACTION_NEXT:
 			if err = system.Right(); err != nil {
 				log.Printf(`key-press-event Right(): %+v`, err)
 			}
-			win.QueueDraw()
 		}
 	})
 
@@ -130,11 +217,19 @@ func main() {
 		// Get the window size.
 		winWidth, winHeight := win.GetSize()
 
+		// Once the slide show has ended, the end may cover the slides.
+		if config.EndAction != conditioning.END_ACTION_STOP && system.Finished() {
+			display.RenderEnd(config, cr, winWidth, winHeight)
+			return
+		}
+
 		// Get the affirmation.
 		affirmationIndex, displayText, displayImage, displayBoth, affirmationFound := system.DisplayTextImage()
+		paused := system.IsPaused()
+		problems := system.Problems()
 
 		// Get a cached slide if there is one.
-		cachedPixbuf, cacheFound := system.GetCachedSlide(affirmationIndex, winWidth, winHeight)
+		cachedPixbuf, cacheFound := cache.GetCachedSlide(affirmationIndex, winWidth, winHeight)
 		if cacheFound && displayBoth {
 
 			// Paint the cached slide.
@@ -143,49 +238,11 @@ func main() {
 
 		} else {
 
-			// Paint the screen black.
-			cr.SetSourceRGB(0, 0, 0)
-			cr.Rectangle(0, 0, 10000, 10000) // Lots of black.
-			cr.Fill()
-
-			// Pick the shortest ratio.
-			widthRatio := float64(winWidth) / float64(config.ScreenWidth)
-			heightRatio := float64(winHeight) / float64(config.ScreenHeight)
-			ratio := 1.0
-			switch {
-			case widthRatio < heightRatio:
-				ratio = widthRatio
-			case heightRatio < widthRatio:
-				ratio = heightRatio
-			}
-
-			// Pick the offset that matters.
-			var xOffset, yOffset float64
-			switch {
-			case widthRatio < heightRatio:
-				// We're centering on the y axis.
-				yOffset = (float64(winHeight) - float64(config.ScreenHeight)*ratio) / 2.0
-			case heightRatio < widthRatio:
-				// We're centering on the x axis.
-				xOffset = (float64(winWidth) - float64(config.ScreenWidth)*ratio) / 2.0
-			}
-
-			// Create a matrix that represents this transform.
-			matrix := cairo.NewMatrix(ratio, 0.0, 0.0, ratio, xOffset, yOffset)
-			cr.Transform(matrix)
+			// Render the slide letterboxed in the window.
+			display.RenderSlide(config, cr, winWidth, winHeight, displayText, displayImage, displayBoth && affirmationFound)
 
-			// If we have an affirmation render it.
-			if affirmationFound {
-				if displayImage != nil {
-					conditioning.RenderImage(config, cr, *displayImage)
-				}
-				if displayBoth {
-					conditioning.RenderAffirmation(config, cr, displayText)
-				}
-			}
-
-			// Is the the whole slide?
-			if displayBoth {
+			// Is the the whole slide, with nothing drawn over it?
+			if displayBoth && !paused && !presenter.showingSpeed() && len(problems) == 0 {
 				// Attempt to cache the window image.
 				winGdk, err := win.GetWindow()
 				if err != nil {
@@ -196,11 +253,27 @@ func main() {
 						log.Printf("winGdk.PixbufGetFromWindow() err: %+v", err)
 					} else {
 						// No error, we can cache this.
-						system.CacheSlide(affirmationIndex, winWidth, winHeight, pixbuf)
+						cache.CacheSlide(affirmationIndex, winWidth, winHeight, pixbuf)
 					}
 				}
 			}
 		}
+
+		// Show that the slide show is holding on this slide.
+		if paused {
+			display.RenderPausedIndicator(config, cr, winWidth, winHeight)
+		}
+
+		// Show what went wrong loading the affirmations.
+		if len(problems) > 0 {
+			display.RenderProblems(config, cr, winWidth, winHeight, problems)
+		}
+
+		// Show a speed change for a while.
+		if presenter.showingSpeed() {
+			percent, interval := system.Speed()
+			display.RenderSpeedOverlay(config, cr, winWidth, winHeight, percent, interval)
+		}
 	})
 
 	win.QueueDraw()
@@ -208,10 +281,60 @@ func main() {
 	// Set the default window size.
 	win.SetDefaultSize(int(config.ScreenWidth), int(config.ScreenHeight))
 
+	// Fill the monitor, the slides letterbox to any size.
+	if config.Fullscreen {
+		win.Fullscreen()
+	}
+
 	// Recursively show all widgets contained in this window.
 	win.ShowAll()
 
+	// Run unattended.
+	if config.Kiosk {
+		hideCursor(win)
+	}
+	if config.AutoStart {
+		if err = system.Resume(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Reload whenever the affirmations or images change.
+	stopWatching := system.Watch()
+
 	// Begin executing the GTK main loop.  This blocks until
 	// gtk.MainQuit() is run.
 	gtk.Main()
+
+	stopWatching()
+
+	// Remember where this session left off.
+	if err = system.SaveState(); err != nil {
+		log.Printf(`SaveState(): %+v`, err)
+	}
+}
+
+// hideCursor hides the mouse cursor over a shown window.
+func hideCursor(win *gtk.Window) {
+	display, err := gdk.DisplayGetDefault()
+	if err != nil {
+		log.Printf("gdk.DisplayGetDefault() err: %+v", err)
+		return
+	}
+	cursor, err := gdk.CursorNewFromName(display, "none")
+	if err != nil {
+		log.Printf("gdk.CursorNewFromName() err: %+v", err)
+		return
+	}
+	winGdk, err := win.GetWindow()
+	if err != nil {
+		log.Printf("win.GetWindow() err: %+v", err)
+		return
+	}
+	winGdk.SetCursor(cursor)
+}
+
+// imagePathFor finds the images folder next to an affirmations file.
+func imagePathFor(affirmationFilename string) (imagePath string) {
+	return filepath.Dir(affirmationFilename) + "/images/"
 }
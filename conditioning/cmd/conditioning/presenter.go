@@ -0,0 +1,74 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"glemzurg/conditioning"
+)
+
+const (
+	// How long a speed change shows on screen.
+	_SPEED_SHOWN_MILLI = 2000
+)
+
+// gtkPresenter redraws the window whenever the system changes.
+type gtkPresenter struct {
+	win             *gtk.Window            // The window, once it exists.
+	system          *conditioning.System   // The system, once it exists.
+	cache           *slideCache            // The rendered slides.
+	endAction       conditioning.EndAction // What happens when the slide show ends.
+	speedShownUntil time.Time              // When to stop showing the last speed change.
+}
+
+// StateChanged hands the change to the GTK main loop, since the slide show runs in its own goroutine.
+func (p *gtkPresenter) StateChanged(event conditioning.Event) {
+	if _, err := glib.IdleAdd(func() { p.present(event) }); err != nil {
+		log.Printf(`StateChanged() IdleAdd(): %+v`, err)
+	}
+}
+
+// present shows a change, on the GTK main loop.
+func (p *gtkPresenter) present(event conditioning.Event) {
+
+	// Newly loaded slides need rendering again.
+	if event == conditioning.EVENT_SLIDES_LOADED {
+		p.cache.Clear()
+	}
+
+	// The session is over.
+	if event == conditioning.EVENT_SLIDE_SHOW_ENDED && p.endAction == conditioning.END_ACTION_QUIT {
+		gtk.MainQuit()
+		return
+	}
+
+	// Nothing to draw before the window exists.
+	if p.win == nil {
+		return
+	}
+	p.win.QueueDraw()
+
+	// The title may have changed with the affirmations.
+	if event == conditioning.EVENT_SLIDES_LOADED && p.system != nil {
+		p.win.SetTitle(p.system.Title())
+	}
+
+	// Show a new speed for a while, then draw again without it.
+	if event == conditioning.EVENT_SPEED_CHANGED {
+		p.speedShownUntil = time.Now().Add(_SPEED_SHOWN_MILLI * time.Millisecond)
+		if _, err := glib.TimeoutAdd(_SPEED_SHOWN_MILLI, func() bool {
+			p.win.QueueDraw()
+			return false // Only once.
+		}); err != nil {
+			log.Printf(`present() TimeoutAdd(): %+v`, err)
+		}
+	}
+}
+
+// showingSpeed is whether a speed change should be on screen, on the GTK main loop.
+func (p *gtkPresenter) showingSpeed() (showing bool) {
+	return time.Now().Before(p.speedShownUntil)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/gotk3/gotk3/gdk"
+)
+
+// slideCache holds rendered slides for quick drawing. It is only used from the GTK main loop.
+type slideCache struct {
+	cachedWidth  int
+	cachedHeight int
+	cachedSlides map[int]*gdk.Pixbuf
+}
+
+// newSlideCache creates an empty slide cache.
+func newSlideCache() (cache *slideCache) {
+	return &slideCache{
+		cachedSlides: map[int]*gdk.Pixbuf{},
+	}
+}
+
+// CacheSlide caches a slide for quick rendering.
+func (c *slideCache) CacheSlide(affirmationIndex, winWidth, winHeight int, pixbuf *gdk.Pixbuf) {
+
+	// Clear the cache if our window size has changed.
+	c.clearIfNecessary(winWidth, winHeight)
+
+	// Cache the slide.
+	c.cachedSlides[affirmationIndex] = pixbuf
+}
+
+// GetCachedSlide gets a cached slide if there is one for quick rendering.
+func (c *slideCache) GetCachedSlide(affirmationIndex, winWidth, winHeight int) (pixbuf *gdk.Pixbuf, found bool) {
+
+	// Clear the cache if our window size has changed.
+	c.clearIfNecessary(winWidth, winHeight)
+
+	// Get the cached value.
+	pixbuf, found = c.cachedSlides[affirmationIndex]
+	if !found {
+		return nil, false
+	}
+
+	return pixbuf, true
+}
+
+// Clear empties the cache.
+func (c *slideCache) Clear() {
+	c.clearIfNecessary(0, 0) // Passing 0, 0 should trigger a cache clear.
+}
+
+// clearIfNecessary clears the cache of prerendered slides if width or height changed
+func (c *slideCache) clearIfNecessary(winWidth, winHeight int) {
+
+	// Is this still the proper cache.
+	if winWidth == c.cachedWidth && winHeight == c.cachedHeight {
+		// The cache is still good.
+		return
+	}
+
+	c.cachedWidth = winWidth
+	c.cachedHeight = winHeight
+	c.cachedSlides = map[int]*gdk.Pixbuf{}
+}
@@ -0,0 +1,185 @@
+package conditioning
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The shortcut colors.
+	BLACK = "black"
+	WHITE = "white"
+	AUTO  = "auto" // Black or white, whichever stands out from what is behind the text.
+)
+
+// _NAMED_COLORS are the colors that can be given by name, as hex.
+var _NAMED_COLORS = map[string]string{
+	"black":       "#000000",
+	"white":       "#ffffff",
+	"gray":        "#808080",
+	"grey":        "#808080",
+	"silver":      "#c0c0c0",
+	"red":         "#ff0000",
+	"orange":      "#ffa500",
+	"yellow":      "#ffff00",
+	"gold":        "#ffd700",
+	"green":       "#008000",
+	"lime":        "#00ff00",
+	"teal":        "#008080",
+	"cyan":        "#00ffff",
+	"blue":        "#0000ff",
+	"navy":        "#000080",
+	"purple":      "#800080",
+	"magenta":     "#ff00ff",
+	"pink":        "#ffc0cb",
+	"brown":       "#a52a2a",
+	"transparent": "#00000000",
+}
+
+// Color is a color with transparency, each part 0.0-1.0.
+type Color struct {
+	Red   float64
+	Green float64
+	Blue  float64
+	Alpha float64 // 0.0 is invisible, 1.0 is solid.
+}
+
+// ParseColor parses a color name like "gold", or hex like "#fc0", "#ffcc00" or "#ffcc0080" (with transparency).
+func ParseColor(text string) (color Color, err error) {
+	hex := strings.ToLower(text)
+	if named, found := _NAMED_COLORS[hex]; found {
+		hex = named
+	}
+	if !strings.HasPrefix(hex, "#") {
+		return Color{}, Errorf(`invalid color: '%s'`, text)
+	}
+	hex = hex[1:]
+
+	// Short hex has a single digit for each part.
+	if len(hex) == 3 || len(hex) == 4 {
+		var long strings.Builder
+		for _, digit := range hex {
+			long.WriteRune(digit)
+			long.WriteRune(digit)
+		}
+		hex = long.String()
+	}
+
+	// Solid unless there is transparency.
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return Color{}, Errorf(`invalid color: '%s'`, text)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, Errorf(`invalid color: '%s'`, text)
+	}
+
+	return Color{
+		Red:   float64(value>>24&0xff) / 255,
+		Green: float64(value>>16&0xff) / 255,
+		Blue:  float64(value>>8&0xff) / 255,
+		Alpha: float64(value&0xff) / 255,
+	}, nil
+}
+
+// validColor checks a color parses.
+func validColor(text string) (valid bool) {
+	_, err := ParseColor(text)
+	return err == nil
+}
+
+// validTextColor checks a text color parses, or is picked automatically.
+func validTextColor(text string) (valid bool) {
+	return text == AUTO || validColor(text)
+}
+
+// luminance is how bright a color looks, 0.0-1.0, ignoring transparency.
+func (c Color) luminance() (luminance float64) {
+	linear := func(part float64) float64 {
+		if part <= 0.03928 {
+			return part / 12.92
+		}
+		return math.Pow((part+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.Red) + 0.7152*linear(c.Green) + 0.0722*linear(c.Blue)
+}
+
+// contrastRatio is how much two colors stand out from each other, from 1 (the same) to 21 (black and white).
+func contrastRatio(a, b Color) (ratio float64) {
+	lighter, darker := a.luminance(), b.luminance()
+	if lighter < darker {
+		lighter, darker = darker, lighter
+	}
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// dark is true if a color stands out more against white than black.
+func (c Color) dark() (dark bool) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+	return contrastRatio(c, white) > contrastRatio(c, black)
+}
+
+// contrasting is black or white, whichever stands out more from a color.
+func (c Color) contrasting() (contrasting Color) {
+	if c.dark() {
+		contrasting, _ = ParseColor(WHITE)
+		return contrasting
+	}
+	contrasting, _ = ParseColor(BLACK)
+	return contrasting
+}
+
+// textColors works out the colors of some text, from its own settings or else the config.
+func textColors(config Config, textProperties TextProperties) (color, outlineColor Color, outlineScale, opacity float64) {
+
+	// The text is white unless set.
+	colorName := WHITE
+	if config.TextColor != "" {
+		colorName = config.TextColor
+	}
+	if textProperties.Color != "" {
+		colorName = textProperties.Color
+	}
+	if colorName == AUTO {
+		colorName = WHITE // Until what is behind the text is known.
+	}
+	color, _ = ParseColor(colorName) // Validated with the config and affirmations.
+
+	// The outline stands out from the text unless set.
+	outlineColor = color.contrasting()
+	outlineColorName := config.OutlineColor
+	if textProperties.OutlineColor != "" {
+		outlineColorName = textProperties.OutlineColor
+	}
+	if outlineColorName != "" {
+		outlineColor, _ = ParseColor(outlineColorName)
+	}
+
+	// Dark text has the black text outline, light text the white.
+	outlineScale = config.WhiteOutlineScale
+	if color.dark() {
+		outlineScale = config.BlackOutlineScale
+	}
+	if config.OutlineScale != 0 {
+		outlineScale = config.OutlineScale
+	}
+	if textProperties.OutlineScale != 0 {
+		outlineScale = textProperties.OutlineScale
+	}
+
+	// Solid unless set.
+	opacity = 1
+	if config.TextOpacity != 0 {
+		opacity = config.TextOpacity
+	}
+	if textProperties.Opacity != 0 {
+		opacity = textProperties.Opacity
+	}
+
+	return color, outlineColor, outlineScale, opacity
+}
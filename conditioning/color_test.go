@@ -0,0 +1,101 @@
+package conditioning
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type ColorSuite struct{}
+
+var _ = Suite(&ColorSuite{})
+
+// Add the tests.
+
+func (s *ColorSuite) Test_ParseColor(c *C) {
+	tests := []struct {
+		text   string
+		color  Color
+		errstr string
+	}{
+		{"black", Color{0, 0, 0, 1}, ``},
+		{"White", Color{1, 1, 1, 1}, ``},
+		{"transparent", Color{0, 0, 0, 0}, ``},
+		{"#ff0000", Color{1, 0, 0, 1}, ``},
+		{"#00FF0033", Color{0, 1, 0, 0.2}, ``},
+		{"#00f", Color{0, 0, 1, 1}, ``},
+		{"#00f0", Color{0, 0, 1, 0}, ``},
+		{"", Color{}, `invalid color: ''`},
+		{"beige", Color{}, `invalid color: 'beige'`},
+		{"ff0000", Color{}, `invalid color: 'ff0000'`},
+		{"#ff000", Color{}, `invalid color: '#ff000'`},
+		{"#gg0000", Color{}, `invalid color: '#gg0000'`},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		color, err := ParseColor(test.text)
+		if test.errstr == "" {
+			c.Check(err, IsNil, comment)
+			c.Check(color, Equals, test.color, comment)
+		} else {
+			c.Check(err, ErrorEquals, test.errstr, comment)
+		}
+	}
+}
+
+func (s *ColorSuite) Test_Contrast(c *C) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+	gold, _ := ParseColor("gold")
+	navy, _ := ParseColor("navy")
+
+	c.Check(contrastRatio(black, white), Equals, 21.0)
+	c.Check(contrastRatio(white, black), Equals, 21.0)
+	c.Check(contrastRatio(gold, gold), Equals, 1.0)
+
+	c.Check(black.dark(), Equals, true)
+	c.Check(navy.dark(), Equals, true)
+	c.Check(white.dark(), Equals, false)
+	c.Check(gold.dark(), Equals, false)
+
+	c.Check(navy.contrasting(), Equals, white)
+	c.Check(gold.contrasting(), Equals, black)
+}
+
+func (s *ColorSuite) Test_TextColors(c *C) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+	gold, _ := ParseColor("gold")
+	navy, _ := ParseColor("navy")
+	config := Config{BlackOutlineScale: 0.07, WhiteOutlineScale: 0.25}
+
+	// White with a black outline unless set.
+	color, outlineColor, outlineScale, opacity := textColors(config, TextProperties{})
+	c.Check(color, Equals, white)
+	c.Check(outlineColor, Equals, black)
+	c.Check(outlineScale, Equals, 0.25)
+	c.Check(opacity, Equals, 1.0)
+
+	// Black text has a white outline, of the black outline scale.
+	color, outlineColor, outlineScale, _ = textColors(config, TextProperties{Color: BLACK})
+	c.Check(color, Equals, black)
+	c.Check(outlineColor, Equals, white)
+	c.Check(outlineScale, Equals, 0.07)
+
+	// The config sets the defaults.
+	config.TextColor = "gold"
+	config.OutlineColor = "navy"
+	config.OutlineScale = 0.1
+	config.TextOpacity = 0.5
+	color, outlineColor, outlineScale, opacity = textColors(config, TextProperties{})
+	c.Check(color, Equals, gold)
+	c.Check(outlineColor, Equals, navy)
+	c.Check(outlineScale, Equals, 0.1)
+	c.Check(opacity, Equals, 0.5)
+
+	// The affirmation wins.
+	color, outlineColor, outlineScale, opacity = textColors(config, TextProperties{Color: WHITE, OutlineColor: "gold", OutlineScale: 0.2, Opacity: 0.8})
+	c.Check(color, Equals, white)
+	c.Check(outlineColor, Equals, gold)
+	c.Check(outlineScale, Equals, 0.2)
+	c.Check(opacity, Equals, 0.8)
+}
@@ -1,26 +1,82 @@
 package conditioning
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"os"
 )
 
+const (
+	// What happens when a slide show ends.
+	END_ACTION_STOP    EndAction = ""        // Stay on the last slide.
+	END_ACTION_QUIT    EndAction = "quit"    // Close the program.
+	END_ACTION_CLOSING EndAction = "closing" // Show the closing message.
+	END_ACTION_BLACK   EndAction = "black"   // Show nothing.
+)
+
+// EndAction is what happens when a slide show ends.
+type EndAction string
+
 // Config is the configuration for the system.
 type Config struct {
 
 	// The logic.
 	SleepMilli uint // How long to show each slide.
 
+	// The automatic slide time, from the number of words (unused if WordsPerMinute is 0).
+	WordsPerMinute uint // The reading speed used to time each slide.
+	MinSleepMilli  uint // The shortest time to show a slide (required).
+	MaxSleepMilli  uint // The longest time to show a slide (0 for no limit).
+
+	// The session.
+	AutoStart       bool      // If true, start the slide show on launch.
+	StopAfterCycles uint      // End after this many full passes through the affirmations (0 to loop forever).
+	StopAfterMilli  uint      // End after playing this long (0 to loop forever).
+	EndAction       EndAction // What happens when the slide show ends.
+	ClosingMessage  string    // The closing slide, for the "closing" end action.
+
+	// The parsing.
+	Strict bool // If true, refuse to load an affirmations file with parse errors.
+
+	// The keys.
+	KeyBindings map[string]Action // Key names (like "Page_Down" or "j") to actions, on top of the default keys.
+
 	// The screen.
 	ScreenWidth  uint // The basic screen width.
 	ScreenHeight uint // The basic screen height.
+	Fullscreen   bool // If true, fill the monitor instead of opening a window.
+	Kiosk        bool // If true, run unattended: fullscreen, no cursor, the slide show playing, and no keys that change what is shown.
 
 	// The font.
 	FontFace          string  // The default font face.
 	FontSize          uint    // The default font size.
 	WhiteOutlineScale float64 // For white text. The 0.0-1.0 % of the font size for the outline (only half will show).
 	BlackOutlineScale float64 // For black text. The 0.0-1.0 % of the font size for the outline (only half will show).
+
+	// The color.
+	TextColor    string  // The text color, a name or hex like "#ffcc00" or "#ffcc0080", or "auto" (white if not set).
+	OutlineColor string  // The outline color (black or white, whichever stands out from the text, if not set).
+	OutlineScale float64 // The 0.0-1.0 % of the font size for the outline of any color (0 for WhiteOutlineScale or BlackOutlineScale).
+	TextOpacity  float64 // How solid the text and outline are, 0.0-1.0 (0 for solid).
+	MinContrast  float64 // The contrast ratio text should have against what is behind it, 1-21 (0 for 4.5).
+
+	// The effects behind the text.
+	ShadowColor   string // The color of a soft shadow behind the text, a name or hex (no shadow if not set).
+	ShadowOffsetX int    // How far right of the text the shadow is (0 for both offsets is 4, 4).
+	ShadowOffsetY int    // How far below the text the shadow is.
+	ShadowBlur    uint   // How far the shadow softens (0 for 4).
+	GlowColor     string // The color of a soft halo around the text, a name or hex (no glow if not set).
+	GlowRadius    uint   // How far the glow spreads from the text (0 for 8).
+	PanelColor    string // The color of a box behind the text, a name or hex like "#00000080" (no panel if not set).
+	PanelPadding  uint   // Between the text and the edge of the panel (0 for 16).
+	PanelRadius   uint   // How round the corners of the panel are (0 for 12).
+
+	// The text layout.
+	TextWidth   uint      // The widest text can be before wrapping onto more lines (0 to never wrap).
+	TextHeight  uint      // The tallest text can be when shrinking to fit (0 for the screen height).
+	TextAlign   TextAlign // How wrapped text lines up: left, center, right or justify (centered if not set).
+	ShrinkToFit bool      // If true, lower the font size until the text fits in TextWidth and TextHeight.
 }
 
 // Validate the config is well-formed.
@@ -28,6 +84,24 @@ func (c *Config) Validate() (err error) {
 	if c.SleepMilli <= 0 {
 		return Errorf(`invalid SleepMilli: %d`, c.SleepMilli)
 	}
+	if c.WordsPerMinute > 0 && c.MinSleepMilli <= 0 {
+		return Errorf(`invalid MinSleepMilli: %d`, c.MinSleepMilli)
+	}
+	if c.MaxSleepMilli != 0 && c.MaxSleepMilli < c.MinSleepMilli {
+		return Errorf(`invalid MaxSleepMilli: %d (less than MinSleepMilli %d)`, c.MaxSleepMilli, c.MinSleepMilli)
+	}
+	switch c.EndAction {
+	case END_ACTION_STOP, END_ACTION_QUIT, END_ACTION_BLACK:
+	case END_ACTION_CLOSING:
+		if c.ClosingMessage == "" {
+			return Errorf(`invalid ClosingMessage: '%s' (required for EndAction '%s')`, c.ClosingMessage, c.EndAction)
+		}
+	default:
+		return Errorf(`invalid EndAction: '%s'`, c.EndAction)
+	}
+	if err = validateKeyBindings(c.KeyBindings); err != nil {
+		return err
+	}
 	if c.ScreenWidth <= 0 {
 		return Errorf(`invalid ScreenWidth: %d`, c.ScreenWidth)
 	}
@@ -46,6 +120,33 @@ func (c *Config) Validate() (err error) {
 	if c.WhiteOutlineScale <= 0 {
 		return Errorf(`invalid WhiteOutlineScale: %+v`, c.WhiteOutlineScale)
 	}
+	if c.TextColor != "" && !validTextColor(c.TextColor) {
+		return Errorf(`invalid TextColor: '%s'`, c.TextColor)
+	}
+	if c.OutlineColor != "" && !validColor(c.OutlineColor) {
+		return Errorf(`invalid OutlineColor: '%s'`, c.OutlineColor)
+	}
+	if c.OutlineScale < 0 || c.OutlineScale > 1 {
+		return Errorf(`invalid OutlineScale: %+v`, c.OutlineScale)
+	}
+	if c.TextOpacity < 0 || c.TextOpacity > 1 {
+		return Errorf(`invalid TextOpacity: %+v`, c.TextOpacity)
+	}
+	if c.ShadowColor != "" && !validColor(c.ShadowColor) {
+		return Errorf(`invalid ShadowColor: '%s'`, c.ShadowColor)
+	}
+	if c.GlowColor != "" && !validColor(c.GlowColor) {
+		return Errorf(`invalid GlowColor: '%s'`, c.GlowColor)
+	}
+	if c.PanelColor != "" && !validColor(c.PanelColor) {
+		return Errorf(`invalid PanelColor: '%s'`, c.PanelColor)
+	}
+	if c.MinContrast != 0 && (c.MinContrast < 1 || c.MinContrast > 21) {
+		return Errorf(`invalid MinContrast: %+v`, c.MinContrast)
+	}
+	if !validTextAlign(c.TextAlign) {
+		return Errorf(`invalid TextAlign: '%s'`, c.TextAlign)
+	}
 	return nil
 }
 
@@ -73,3 +174,36 @@ func LoadConfig(configFilename string) (config Config, err error) {
 	// The populated config.
 	return config, nil
 }
+
+// updateConfigFile changes some settings in a config file, leaving every other setting as it was written.
+func updateConfigFile(configFilename string, settings map[string]interface{}) (err error) {
+
+	// Load the settings as written, keeping the numbers as they are.
+	contents, err := ioutil.ReadFile(configFilename)
+	if err != nil {
+		return Error(err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	written := map[string]interface{}{}
+	if err = decoder.Decode(&written); err != nil {
+		return Error(err)
+	}
+
+	// Change just these settings.
+	for name, value := range settings {
+		written[name] = value
+	}
+
+	// Format the config like the example config.
+	if contents, err = json.MarshalIndent(written, "", "\t"); err != nil {
+		return Error(err)
+	}
+
+	// Write the file.
+	if err = ioutil.WriteFile(configFilename, append(contents, '\n'), 0644); err != nil {
+		return Error(err)
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package conditioning
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "gopkg.in/check.v1" // https://labix.org/gocheck
 )
 
@@ -31,6 +35,106 @@ func (s *ConfigSuite) Test_Validate(c *C) {
 			errstr: ``,
 		},
 
+		// Automatic slide times.
+		{
+			config: Config{
+				SleepMilli:        1,
+				WordsPerMinute:    200,
+				MinSleepMilli:     1000,
+				MaxSleepMilli:     10000,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: ``,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				WordsPerMinute:    200,
+				MinSleepMilli:     1000,
+				MaxSleepMilli:     999,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid MaxSleepMilli: 999 (less than MinSleepMilli 1000)`,
+		},
+
+		{
+			config: Config{
+				SleepMilli:        1,
+				WordsPerMinute:    200,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid MinSleepMilli: 0`,
+		},
+
+		// The end of a session.
+		{
+			config: Config{
+				SleepMilli:        1,
+				StopAfterCycles:   2,
+				EndAction:         END_ACTION_QUIT,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				EndAction:         END_ACTION_CLOSING,
+				ClosingMessage:    "Well done.",
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				EndAction:         END_ACTION_CLOSING,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid ClosingMessage: '' (required for EndAction 'closing')`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				EndAction:         "exit",
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid EndAction: 'exit'`,
+		},
+
 		// Check missing values.
 		{
 			config: Config{
@@ -116,6 +220,235 @@ func (s *ConfigSuite) Test_Validate(c *C) {
 			},
 			errstr: `invalid WhiteOutlineScale: 0`,
 		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				TextWidth:         800,
+				TextAlign:         TEXT_ALIGN_JUSTIFY,
+				ShrinkToFit:       true,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				TextAlign:         "middle",
+			},
+			errstr: `invalid TextAlign: 'middle'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				TextColor:         "#ffcc00",
+				OutlineColor:      "navy",
+				OutlineScale:      0.1,
+				TextOpacity:       0.8,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				TextColor:         "beige",
+			},
+			errstr: `invalid TextColor: 'beige'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				OutlineColor:      "#ffcc0",
+			},
+			errstr: `invalid OutlineColor: '#ffcc0'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				OutlineScale:      1.5,
+			},
+			errstr: `invalid OutlineScale: 1.5`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				TextOpacity:       -0.5,
+			},
+			errstr: `invalid TextOpacity: -0.5`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				TextColor:         AUTO,
+				MinContrast:       7,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				MinContrast:       0.5,
+			},
+			errstr: `invalid MinContrast: 0.5`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				ShadowColor:       "#00000080",
+				ShadowOffsetY:     4,
+				ShadowBlur:        6,
+				GlowColor:         "gold",
+				GlowRadius:        8,
+				PanelColor:        "#00000080",
+				PanelPadding:      16,
+				PanelRadius:       12,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				ShadowColor:       "shadow",
+			},
+			errstr: `invalid ShadowColor: 'shadow'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				GlowColor:         "#gold",
+			},
+			errstr: `invalid GlowColor: '#gold'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+				PanelColor:        "dark",
+			},
+			errstr: `invalid PanelColor: 'dark'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				KeyBindings:       map[string]Action{"Page_Down": ACTION_NEXT, "j": ACTION_PREV, "r": ACTION_NONE},
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				KeyBindings:       map[string]Action{"PageDown": ACTION_NEXT, "j": ACTION_PREV},
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid KeyBindings key: 'PageDown'`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				KeyBindings:       map[string]Action{"Page_Down": "forward"},
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid KeyBindings action: 'forward' (for key 'Page_Down')`,
+		},
+		{
+			config: Config{
+				SleepMilli:        1,
+				KeyBindings:       map[string]Action{"plus": ACTION_SPEED_UP, "+": ACTION_NONE},
+				ScreenWidth:       1,
+				ScreenHeight:      1,
+				FontFace:          "Georgia",
+				FontSize:          1,
+				BlackOutlineScale: 0.5,
+				WhiteOutlineScale: 0.5,
+			},
+			errstr: `invalid KeyBindings key: 'plus' (the same key as '+')`,
+		},
 	}
 	for i, test := range tests {
 		comment := Commentf("Case %v: %v", i, test)
@@ -128,3 +461,30 @@ func (s *ConfigSuite) Test_Validate(c *C) {
 		}
 	}
 }
+
+func (s *ConfigSuite) Test_UpdateConfigFile(c *C) {
+	dir, err := ioutil.TempDir("", "conditioning")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "config.json")
+	c.Assert(ioutil.WriteFile(filename, []byte(`{"SleepMilli": 1500, "FontFace": "Georgia", "BlackOutlineScale": 0.07, "Custom": [1, 2]}`), 0644), IsNil)
+
+	// Only the settings given change, the rest stay as written.
+	c.Assert(updateConfigFile(filename, map[string]interface{}{"SleepMilli": uint(750), "MinSleepMilli": uint(500)}), IsNil)
+	contents, err := ioutil.ReadFile(filename)
+	c.Assert(err, IsNil)
+	c.Check(string(contents), Equals, "{\n"+
+		"\t\"BlackOutlineScale\": 0.07,\n"+
+		"\t\"Custom\": [\n\t\t1,\n\t\t2\n\t],\n"+
+		"\t\"FontFace\": \"Georgia\",\n"+
+		"\t\"MinSleepMilli\": 500,\n"+
+		"\t\"SleepMilli\": 750\n"+
+		"}\n")
+
+	// A file that isn't a config is left alone.
+	c.Assert(ioutil.WriteFile(filename, []byte(`not json`), 0644), IsNil)
+	c.Check(updateConfigFile(filename, map[string]interface{}{"SleepMilli": uint(750)}), NotNil)
+	contents, err = ioutil.ReadFile(filename)
+	c.Assert(err, IsNil)
+	c.Check(string(contents), Equals, `not json`)
+}
@@ -0,0 +1,162 @@
+package conditioning
+
+const (
+	// The contrast ratio text should have against what is behind it, if not configured.
+	_DEFAULT_MIN_CONTRAST = 4.5
+)
+
+// imagePixels is the pixels of an image placed on the screen.
+type imagePixels struct {
+	X         int    // Coordinate on the screen.
+	Y         int    // Coordinate on the screen.
+	Width     int    // Width in pixels.
+	Height    int    // Height in pixels.
+	Rowstride int    // Bytes from the start of one row to the next.
+	Channels  int    // Bytes in each pixel: red, green, blue, and maybe alpha.
+	Pixels    []byte // The pixel data.
+}
+
+// minContrast is the contrast ratio text should have against what is behind it.
+func minContrast(config Config) (ratio float64) {
+	if config.MinContrast == 0 {
+		return _DEFAULT_MIN_CONTRAST
+	}
+	return config.MinContrast
+}
+
+// pixelsOf gets the pixels of a displayed image.
+func pixelsOf(displayImage *DisplayImage) (image *imagePixels) {
+	if displayImage == nil || displayImage.Image == nil {
+		return nil
+	}
+	pixels := displayImage.Image.Pixels()
+	return &imagePixels{
+		X:         int(displayImage.X),
+		Y:         int(displayImage.Y),
+		Width:     pixels.Width,
+		Height:    pixels.Height,
+		Rowstride: pixels.Rowstride,
+		Channels:  pixels.Channels,
+		Pixels:    pixels.Pixels,
+	}
+}
+
+// averageColor is the average color of a rectangle of the black screen, with an image (if any) on it.
+func averageColor(image *imagePixels, x, y, width, height int) (color Color) {
+	color.Alpha = 1
+	if width <= 0 || height <= 0 {
+		return color
+	}
+
+	// Add up the image pixels in the rectangle, the black screen adding nothing.
+	var red, green, blue float64
+	if image != nil {
+		for row := y; row < y+height; row++ {
+			imageRow := row - image.Y
+			if imageRow < 0 || imageRow >= image.Height {
+				continue
+			}
+			for column := x; column < x+width; column++ {
+				imageColumn := column - image.X
+				if imageColumn < 0 || imageColumn >= image.Width {
+					continue
+				}
+				pixel := image.Pixels[imageRow*image.Rowstride+imageColumn*image.Channels:]
+				alpha := 1.0
+				if image.Channels == 4 {
+					alpha = float64(pixel[3]) / 255 // Over the black screen.
+				}
+				red += float64(pixel[0]) / 255 * alpha
+				green += float64(pixel[1]) / 255 * alpha
+				blue += float64(pixel[2]) / 255 * alpha
+			}
+		}
+	}
+
+	pixels := float64(width * height)
+	color.Red, color.Green, color.Blue = red/pixels, green/pixels, blue/pixels
+	return color
+}
+
+// textBackground is the average color behind some text, a panel (if any) laid over the image (if any).
+func textBackground(displayText DisplayText, displayImage *DisplayImage) (background Color) {
+	background = averageColor(pixelsOf(displayImage), displayText.X, displayText.Y, displayText.Width, displayText.Height)
+	if displayText.Panel.Color != "" {
+		panelColor, _ := ParseColor(displayText.Panel.Color) // Validated with the config and affirmations.
+		background = panelColor.over(background, 1)
+	}
+	return background
+}
+
+// over is a color laid over a background, as it looks.
+func (c Color) over(background Color, opacity float64) (color Color) {
+	alpha := c.Alpha * opacity
+	return Color{
+		Red:   c.Red*alpha + background.Red*(1-alpha),
+		Green: c.Green*alpha + background.Green*(1-alpha),
+		Blue:  c.Blue*alpha + background.Blue*(1-alpha),
+		Alpha: 1,
+	}
+}
+
+// textContrast is the contrast ratio of text against what is behind it.
+func textContrast(displayText DisplayText, background Color) (ratio float64) {
+	return contrastRatio(displayText.Color.over(background, displayText.Opacity), background)
+}
+
+// outlinedContrast is the contrast ratio of text against what is behind it, counting the outline around the text.
+// Text stands out if it stands out from the background, or from an outline that is drawn around it.
+func outlinedContrast(displayText DisplayText, background Color) (ratio float64) {
+	ratio = textContrast(displayText, background)
+	if !displayText.Outline || displayText.OutlineScale <= 0 {
+		return ratio
+	}
+	outline := displayText.OutlineColor.over(background, displayText.Opacity)
+	if outlineRatio := textContrast(displayText, outline); outlineRatio > ratio {
+		ratio = outlineRatio
+	}
+	return ratio
+}
+
+// matchBackground picks black or white for "auto" colored text, whichever stands out more from the image and panel behind it.
+func matchBackground(config Config, textProperties TextProperties, displayText DisplayText, displayImage *DisplayImage) (matched DisplayText) {
+	if !autoColor(config, textProperties) {
+		return displayText
+	}
+	return matchColor(config, textProperties, displayText, textBackground(displayText, displayImage))
+}
+
+// matchColor picks black or white for "auto" colored text, whichever stands out more from the background.
+// If neither stands out enough, the outline stands out from the text instead of any configured outline color.
+func matchColor(config Config, textProperties TextProperties, displayText DisplayText, background Color) (matched DisplayText) {
+	if !autoColor(config, textProperties) {
+		return displayText
+	}
+
+	// Try both.
+	best := 0.0
+	for _, color := range []string{WHITE, BLACK} {
+		textProperties.Color = color
+		try := displayText
+		try.Color, try.OutlineColor, try.OutlineScale, try.Opacity = textColors(config, textProperties)
+		if contrast := textContrast(try, background); contrast > best {
+			best = contrast
+			matched = try
+		}
+	}
+
+	// Lean on the outline.
+	if best < minContrast(config) {
+		matched.OutlineColor = matched.Color.contrasting()
+	}
+
+	return matched
+}
+
+// autoColor is true if text takes its color from what is behind it.
+func autoColor(config Config, textProperties TextProperties) (auto bool) {
+	if textProperties.Color != "" {
+		return textProperties.Color == AUTO
+	}
+	return config.TextColor == AUTO
+}
@@ -0,0 +1,133 @@
+package conditioning
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type ContrastSuite struct{}
+
+var _ = Suite(&ContrastSuite{})
+
+// Add the tests.
+
+func (s *ContrastSuite) Test_AverageColor(c *C) {
+
+	// A 2x2 image at 10,10: white, red on the top row; half see-through white, blue on the bottom.
+	image := &imagePixels{
+		X:         10,
+		Y:         10,
+		Width:     2,
+		Height:    2,
+		Rowstride: 8,
+		Channels:  4,
+		Pixels: []byte{
+			255, 255, 255, 255, 255, 0, 0, 255,
+			255, 255, 255, 51, 0, 0, 255, 255,
+		},
+	}
+
+	tests := []struct {
+		x, y, width, height int
+		color               Color
+	}{
+		{10, 10, 1, 1, Color{1, 1, 1, 1}},         // White.
+		{11, 10, 1, 1, Color{1, 0, 0, 1}},         // Red.
+		{10, 11, 1, 1, Color{0.2, 0.2, 0.2, 1}},   // See-through white on the black screen.
+		{10, 10, 2, 1, Color{1, 0.5, 0.5, 1}},     // White and red.
+		{9, 10, 2, 1, Color{0.5, 0.5, 0.5, 1}},    // Half on the black screen.
+		{0, 0, 5, 5, Color{0, 0, 0, 1}},           // All black screen.
+		{10, 10, 0, 0, Color{0, 0, 0, 1}},         // Nothing.
+		{10, 10, 2, 2, Color{0.55, 0.3, 0.55, 1}}, // All of it.
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(averageColor(image, test.x, test.y, test.width, test.height), Equals, test.color, comment)
+	}
+
+	// No image is the black screen.
+	c.Check(averageColor(nil, 0, 0, 10, 10), Equals, Color{0, 0, 0, 1})
+
+	// Without transparency.
+	image = &imagePixels{X: 0, Y: 0, Width: 1, Height: 1, Rowstride: 4, Channels: 3, Pixels: []byte{0, 255, 0, 0}}
+	c.Check(averageColor(image, 0, 0, 1, 1), Equals, Color{0, 1, 0, 1})
+}
+
+func (s *ContrastSuite) Test_TextBackground(c *C) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+	displayText := DisplayText{X: 10, Y: 10, Width: 20, Height: 10}
+
+	// The black screen.
+	c.Check(textBackground(displayText, nil), Equals, black)
+
+	// A panel laid over it.
+	displayText.Panel = Panel{Color: WHITE}
+	c.Check(textBackground(displayText, nil), Equals, white)
+	displayText.Panel = Panel{Color: "#ffffff80"}
+	background := textBackground(displayText, nil)
+	c.Check(background.Red > 0.49 && background.Red < 0.51, Equals, true)
+	c.Check(background.Alpha, Equals, 1.0)
+}
+
+func (s *ContrastSuite) Test_MatchColor(c *C) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+	gray, _ := ParseColor("gray")
+	pink, _ := ParseColor("pink")
+	navy, _ := ParseColor("navy")
+	config := Config{BlackOutlineScale: 0.07, WhiteOutlineScale: 0.25, OutlineColor: "navy"}
+
+	// Not automatic.
+	displayText := DisplayText{Color: pink, Opacity: 1}
+	c.Check(matchColor(config, TextProperties{Color: "pink"}, displayText, white), DeepEquals, displayText)
+
+	// Black on a light background, white on a dark one.
+	matched := matchColor(config, TextProperties{Color: AUTO}, displayText, pink)
+	c.Check(matched.Color, Equals, black)
+	c.Check(matched.OutlineColor, Equals, navy)
+	c.Check(matched.OutlineScale, Equals, 0.07)
+	matched = matchColor(config, TextProperties{Color: AUTO}, displayText, navy)
+	c.Check(matched.Color, Equals, white)
+	c.Check(matched.OutlineScale, Equals, 0.25)
+
+	// From the config.
+	config.TextColor = AUTO
+	matched = matchColor(config, TextProperties{}, displayText, pink)
+	c.Check(matched.Color, Equals, black)
+
+	// Neither stands out enough, so the outline does.
+	config.MinContrast = 7
+	matched = matchColor(config, TextProperties{}, displayText, gray)
+	c.Check(matched.Color, Equals, black)
+	c.Check(matched.OutlineColor, Equals, white)
+}
+
+func (s *ContrastSuite) Test_TextContrast(c *C) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+
+	c.Check(textContrast(DisplayText{Color: white, Opacity: 1}, black), Equals, 21.0)
+	c.Check(textContrast(DisplayText{Color: black, Opacity: 1}, black), Equals, 1.0)
+
+	// See-through text stands out less.
+	c.Check(textContrast(DisplayText{Color: white, Opacity: 0.5}, black) < 21.0, Equals, true)
+	c.Check(textContrast(DisplayText{Color: Color{1, 1, 1, 0.5}, Opacity: 1}, black), Equals, textContrast(DisplayText{Color: white, Opacity: 0.5}, black))
+}
+
+func (s *ContrastSuite) Test_OutlinedContrast(c *C) {
+	black, _ := ParseColor(BLACK)
+	white, _ := ParseColor(WHITE)
+	transparent, _ := ParseColor("transparent")
+
+	// Black text on the black screen stands out by its white outline.
+	c.Check(outlinedContrast(DisplayText{Color: black, Opacity: 1, Outline: OUTLINE, OutlineColor: white, OutlineScale: 0.05}, black), Equals, 21.0)
+
+	// Unless the outline isn't drawn.
+	c.Check(outlinedContrast(DisplayText{Color: black, Opacity: 1, Outline: NO_OUTLINE, OutlineColor: white, OutlineScale: 0.05}, black), Equals, 1.0)
+	c.Check(outlinedContrast(DisplayText{Color: black, Opacity: 1, Outline: OUTLINE, OutlineColor: white}, black), Equals, 1.0)
+	c.Check(outlinedContrast(DisplayText{Color: black, Opacity: 1, Outline: OUTLINE, OutlineColor: transparent, OutlineScale: 0.05}, black), Equals, 1.0)
+
+	// Text standing out from the background needs no outline.
+	c.Check(outlinedContrast(DisplayText{Color: white, Opacity: 1, Outline: OUTLINE, OutlineColor: white, OutlineScale: 0.05}, black), Equals, 21.0)
+}
@@ -0,0 +1,55 @@
+package conditioning
+
+import (
+	"fmt"
+	"log"
+)
+
+const (
+	// The severity of a parse diagnostic.
+	SEVERITY_ERROR   DiagnosticSeverity = "error"
+	SEVERITY_WARNING DiagnosticSeverity = "warning"
+)
+
+// DiagnosticSeverity is how serious a parse problem is.
+type DiagnosticSeverity string
+
+// ParseDiagnostic is a single problem found while parsing an affirmations file.
+type ParseDiagnostic struct {
+	File     string             // The file the problem is in.
+	Line     int                // The 1-based line of the problem.
+	Column   int                // The 1-based column of the problem, 0 if it is the whole line.
+	Severity DiagnosticSeverity // How serious the problem is.
+	Message  string             // What is wrong.
+	Token    string             // The offending text.
+}
+
+// String formats the diagnostic like a compiler message.
+func (d ParseDiagnostic) String() string {
+	if d.Column == 0 {
+		// The problem is with the whole line.
+		return fmt.Sprintf(`%s:%d: %s: %s: '%s'`, d.File, d.Line, d.Severity, d.Message, d.Token)
+	}
+	return fmt.Sprintf(`%s:%d:%d: %s: %s: '%s'`, d.File, d.Line, d.Column, d.Severity, d.Message, d.Token)
+}
+
+// countErrors counts the diagnostics that are errors rather than warnings.
+func countErrors(diagnostics []ParseDiagnostic) (count int) {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SEVERITY_ERROR {
+			count++
+		}
+	}
+	return count
+}
+
+// reportDiagnostics logs the problems in an affirmations file, failing on errors in strict mode.
+func reportDiagnostics(config Config, affirmationFilename string, diagnostics []ParseDiagnostic) (err error) {
+	for _, diagnostic := range diagnostics {
+		log.Println(diagnostic)
+	}
+	if errorCount := countErrors(diagnostics); config.Strict && errorCount > 0 {
+		return Errorf(`%d parse errors in '%s'`, errorCount, affirmationFilename)
+	}
+	return nil
+}
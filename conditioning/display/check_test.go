@@ -0,0 +1,9 @@
+package display
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+	"testing"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
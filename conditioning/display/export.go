@@ -0,0 +1,83 @@
+package display
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gotk3/gotk3/cairo"
+
+	"glemzurg/conditioning"
+)
+
+// ExportPNGs renders every affirmation to its own PNG file without a window.
+// A width or height of 0 uses the configured screen size.
+func ExportPNGs(config conditioning.Config, affirmationFilename, imagePath, outputPath string, width, height int) (filenames []string, err error) {
+	width, height = exportSize(config, width, height)
+
+	// Get the affirmations ready to draw.
+	slides, err := conditioning.LoadSlides(NewSlidePreparer(), config, affirmationFilename, imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, slide := range slides {
+
+		// Draw the whole slide offscreen.
+		surface := cairo.CreateImageSurface(cairo.FORMAT_ARGB32, width, height)
+		cr := cairo.Create(surface)
+		RenderSlide(config, cr, width, height, slide.DisplayText, slide.DisplayImage, true)
+
+		// Write it out.
+		filename := filepath.Join(outputPath, fmt.Sprintf("slide-%03d.png", i+1))
+		if err = surface.WriteToPNG(filename); err != nil {
+			return nil, conditioning.Error(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	return filenames, nil
+}
+
+// ExportPDF renders every affirmation to its own page of a PDF file without a window.
+// A width or height of 0 uses the configured screen size.
+func ExportPDF(config conditioning.Config, affirmationFilename, imagePath, outputFilename string, width, height int) (err error) {
+	width, height = exportSize(config, width, height)
+
+	// Get the affirmations ready to draw.
+	slides, err := conditioning.LoadSlides(NewSlidePreparer(), config, affirmationFilename, imagePath)
+	if err != nil {
+		return err
+	}
+
+	// The pages are drawn as vectors wherever possible.
+	surface, err := cairo.CreatePDFSurface(outputFilename, float64(width), float64(height))
+	if err != nil {
+		return conditioning.Error(err)
+	}
+	cr := cairo.Create(surface)
+
+	for _, slide := range slides {
+
+		RenderSlide(config, cr, width, height, slide.DisplayText, slide.DisplayImage, true)
+
+		// Move to the next page.
+		cr.ShowPage()
+	}
+
+	// Closing the surface finishes writing the file.
+	cr.Close()
+	surface.Close()
+
+	return nil
+}
+
+// exportSize picks the size of exported slides, defaulting to the configured screen.
+func exportSize(config conditioning.Config, width, height int) (exportWidth, exportHeight int) {
+	if width <= 0 {
+		width = int(config.ScreenWidth)
+	}
+	if height <= 0 {
+		height = int(config.ScreenHeight)
+	}
+	return width, height
+}
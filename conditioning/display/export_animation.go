@@ -0,0 +1,122 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gotk3/gotk3/cairo"
+
+	"glemzurg/conditioning"
+)
+
+// ExportGIF renders one cycle of the slide show as an animated GIF without a window.
+// A width or height of 0 uses the configured screen size.
+func ExportGIF(config conditioning.Config, affirmationFilename, imagePath, outputFilename string, width, height int, random bool) (err error) {
+	width, height = exportSize(config, width, height)
+
+	// Get the affirmations ready to draw.
+	slides, err := conditioning.LoadSlides(NewSlidePreparer(), config, affirmationFilename, imagePath)
+	if err != nil {
+		return err
+	}
+
+	// Each step is one frame, shown for the step's duration.
+	animation := &gif.GIF{}
+	for _, step := range conditioning.ExportSteps(config, slides, random) {
+		surface := renderStep(config, slides, step, width, height)
+
+		// GIF frames are limited to 256 colors.
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+		draw.FloydSteinberg.Draw(frame, frame.Bounds(), surfaceImage(surface), image.Point{})
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, int(step.Duration/(10*time.Millisecond))) // In 100ths of a second.
+	}
+
+	// Write it out.
+	file, err := os.Create(outputFilename)
+	if err != nil {
+		return conditioning.Error(err)
+	}
+	defer file.Close()
+	if err = gif.EncodeAll(file, animation); err != nil {
+		return conditioning.Error(err)
+	}
+
+	return nil
+}
+
+// ExportFrames renders one cycle of the slide show as numbered PNG frames at a fixed frame rate, ready for a video encoder.
+// A width or height of 0 uses the configured screen size.
+func ExportFrames(config conditioning.Config, affirmationFilename, imagePath, outputPath string, width, height int, fps float64, random bool) (filenames []string, err error) {
+	width, height = exportSize(config, width, height)
+	if fps <= 0 {
+		return nil, conditioning.Errorf(`invalid fps: %+v`, fps)
+	}
+
+	// Get the affirmations ready to draw.
+	slides, err := conditioning.LoadSlides(NewSlidePreparer(), config, affirmationFilename, imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Repeat each step's frame for as long as the step lasts.
+	for _, step := range conditioning.ExportSteps(config, slides, random) {
+		surface := renderStep(config, slides, step, width, height)
+		for i := 0; i < frameCount(step.Duration, fps); i++ {
+			filename := filepath.Join(outputPath, fmt.Sprintf("frame-%05d.png", len(filenames)+1))
+			if err = surface.WriteToPNG(filename); err != nil {
+				return nil, conditioning.Error(err)
+			}
+			filenames = append(filenames, filename)
+		}
+	}
+
+	return filenames, nil
+}
+
+// frameCount is how many frames at a frame rate cover a duration, at least one.
+func frameCount(duration time.Duration, fps float64) (count int) {
+	count = int(duration.Seconds()*fps + 0.5)
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// renderStep draws a step of the slide show offscreen.
+func renderStep(config conditioning.Config, slides []conditioning.Slide, step conditioning.ExportStep, width, height int) (surface *cairo.Surface) {
+	slide := slides[step.AffirmationIndex]
+	surface = cairo.CreateImageSurface(cairo.FORMAT_ARGB32, width, height)
+	cr := cairo.Create(surface)
+	RenderSlide(config, cr, width, height, slide.DisplayText, slide.DisplayImage, step.DisplayBoth)
+	surface.Flush()
+	return surface
+}
+
+// surfaceImage copies a drawn ARGB32 image surface into a go image.
+func surfaceImage(surface *cairo.Surface) (rgba *image.RGBA) {
+	width := surface.GetWidth()
+	height := surface.GetHeight()
+	stride := width * 4 // ARGB32 rows are always 4-byte aligned.
+
+	// Cairo stores each pixel as a native-endian 32-bit ARGB value, which is BGRA in memory on little-endian machines.
+	size := stride * height
+	data := (*[1 << 30]byte)(surface.GetData())[:size:size]
+
+	rgba = image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < size; i += 4 {
+		rgba.Pix[i+0] = data[i+2] // Red.
+		rgba.Pix[i+1] = data[i+1] // Green.
+		rgba.Pix[i+2] = data[i+0] // Blue.
+		rgba.Pix[i+3] = data[i+3] // Alpha.
+	}
+
+	return rgba
+}
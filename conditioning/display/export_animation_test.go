@@ -0,0 +1,31 @@
+package display
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type ExportAnimationSuite struct{}
+
+var _ = Suite(&ExportAnimationSuite{})
+
+// Add the tests.
+
+func (s *ExportAnimationSuite) Test_FrameCount(c *C) {
+	tests := []struct {
+		duration time.Duration
+		fps      float64
+		count    int
+	}{
+		{time.Second, 30, 30},
+		{1500 * time.Millisecond, 24, 36},
+		{10 * time.Millisecond, 30, 1},
+		{0, 30, 1},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(frameCount(test.duration, test.fps), Equals, test.count, comment)
+	}
+}
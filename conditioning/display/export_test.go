@@ -0,0 +1,33 @@
+package display
+
+import (
+	"glemzurg/conditioning"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type ExportSuite struct{}
+
+var _ = Suite(&ExportSuite{})
+
+// Add the tests.
+
+func (s *ExportSuite) Test_ExportSize(c *C) {
+	config := conditioning.Config{ScreenWidth: 1440, ScreenHeight: 900}
+	tests := []struct {
+		width, height             int
+		exportWidth, exportHeight int
+	}{
+		{0, 0, 1440, 900},
+		{1080, 1080, 1080, 1080},
+		{1920, 0, 1920, 900},
+		{-1, 600, 1440, 600},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		exportWidth, exportHeight := exportSize(config, test.width, test.height)
+		c.Check(exportWidth, Equals, test.exportWidth, comment)
+		c.Check(exportHeight, Equals, test.exportHeight, comment)
+	}
+}
@@ -1,28 +1,19 @@
-package conditioning
+package display
 
 import (
 	"fmt"
 
-	"github.com/gotk3/gotk3/cairo"
 	"github.com/gotk3/gotk3/gdk"
+
+	"glemzurg/conditioning"
 )
 
 const (
 	_PRESERVE_ASPECT_RATIO = true
 )
 
-// DisplayImage is everything neded to display image on the screen.
-type DisplayImage struct {
-	// Coordinate.
-	X float64 // Coordinate on the screen.
-	Y float64 // Coordinate on the screen.
-	// Image.
-	Filename string
-	Pixbuf   *gdk.Pixbuf
-}
-
 // PrepareImage prepares a image for display on the screen.
-func PrepareImage(config Config, cr *cairo.Context, imagePath string, affirmationImage AffirmationImage) (displayImage DisplayImage, err error) {
+func PrepareImage(config conditioning.Config, imagePath string, affirmationImage conditioning.AffirmationImage) (displayImage conditioning.DisplayImage, err error) {
 
 	// The full filename.
 	displayImage.Filename = imagePath + affirmationImage.Filename
@@ -31,7 +22,7 @@ func PrepareImage(config Config, cr *cairo.Context, imagePath string, affirmatio
 	pixbuf, err := gdk.PixbufNewFromFileAtScale(displayImage.Filename, int(config.ScreenWidth), int(config.ScreenHeight), _PRESERVE_ASPECT_RATIO)
 	if err != nil {
 		fmt.Printf("%T\n", err)
-		return DisplayImage{}, Error(err)
+		return conditioning.DisplayImage{}, conditioning.Error(err)
 	}
 
 	// Are we changing the size?
@@ -54,7 +45,7 @@ func PrepareImage(config Config, cr *cairo.Context, imagePath string, affirmatio
 		// INTERP_HYPER
 		scaledPixbuf, err := pixbuf.ScaleSimple(destWidth, destHeight, gdk.INTERP_HYPER)
 		if err != nil {
-			return DisplayImage{}, Error(err)
+			return conditioning.DisplayImage{}, conditioning.Error(err)
 		}
 		pixbuf = scaledPixbuf
 	}
@@ -76,7 +67,25 @@ func PrepareImage(config Config, cr *cairo.Context, imagePath string, affirmatio
 	displayImage.Y = float64(centeredY + affirmationImage.OffsetY)
 
 	// Attach the image data itself.
-	displayImage.Pixbuf = pixbuf
+	displayImage.Width = imageWidth
+	displayImage.Height = imageHeight
+	displayImage.Image = pixbufImage{pixbuf: pixbuf}
 
 	return displayImage, nil
 }
+
+// pixbufImage is image data loaded by gdk.
+type pixbufImage struct {
+	pixbuf *gdk.Pixbuf
+}
+
+// Pixels gets the pixel data of the image.
+func (p pixbufImage) Pixels() (pixels conditioning.ImagePixels) {
+	return conditioning.ImagePixels{
+		Width:     p.pixbuf.GetWidth(),
+		Height:    p.pixbuf.GetHeight(),
+		Rowstride: p.pixbuf.GetRowstride(),
+		Channels:  p.pixbuf.GetNChannels(),
+		Pixels:    p.pixbuf.GetPixels(),
+	}
+}
@@ -0,0 +1,100 @@
+package display
+
+import (
+	"strconv"
+
+	"github.com/gotk3/gotk3/cairo"
+	"github.com/gotk3/gotk3/pango"
+
+	"glemzurg/conditioning"
+)
+
+// slidePreparer prepares slides by measuring with pango and loading images with gdk.
+type slidePreparer struct{}
+
+// NewSlidePreparer creates a preparer for slides shown with GTK.
+func NewSlidePreparer() (preparer conditioning.SlidePreparer) {
+	return slidePreparer{}
+}
+
+// PrepareText prepares a text for display on the screen, measured in a context of the screen's size.
+func (slidePreparer) PrepareText(config conditioning.Config, message string, textProperties conditioning.TextProperties) (displayText conditioning.DisplayText) {
+
+	// Create a context of the proper dimensions for sizing everything.
+	surface := cairo.CreateImageSurface(cairo.FORMAT_ARGB32, int(config.ScreenWidth), int(config.ScreenHeight))
+	cr := cairo.Create(surface)
+
+	return PrepareText(config, cr, message, textProperties)
+}
+
+// PrepareImage prepares a image for display on the screen.
+func (slidePreparer) PrepareImage(config conditioning.Config, imagePath string, affirmationImage conditioning.AffirmationImage) (displayImage conditioning.DisplayImage, err error) {
+	return PrepareImage(config, imagePath, affirmationImage)
+}
+
+// PrepareText prepares a text for display on the screen.
+func PrepareText(config conditioning.Config, cr *cairo.Context, message string, textProperties conditioning.TextProperties) (displayText conditioning.DisplayText) {
+
+	// Markup, font, color and effects.
+	displayText = conditioning.StyleText(config, message, textProperties)
+	textLayout := displayText.Layout
+
+	// Compute the absolute position of the text.
+
+	// Create a pango layout.
+	layout := pango.CairoCreateLayout(cr)
+
+	// Lay out the text in a font size.
+	layoutText := func(fontSize int) (runs []conditioning.TextRun, width, height int) {
+		layout.SetFontDescription(fontDescription(displayText.FontFace, uint(fontSize)))
+		return layoutRuns(displayText.PangoMarkup, textLayout, func(markup string) (width, height int) {
+
+			// Set the markup in the mask.
+			layout.SetMarkup(markup, -1)
+
+			// What are the dimensions of the layout.
+			pangoWidth, pangoHeight := layout.GetSize()
+			return pangoWidth / pango.PANGO_SCALE, pangoHeight / pango.PANGO_SCALE
+		})
+	}
+
+	// Shrink the font until the text fits.
+	if textLayout.ShrinkToFit {
+		boxWidth, boxHeight := textLayout.Box(config)
+		displayText.FontSize = uint(fitFontSize(int(displayText.FontSize), func(fontSize int) bool {
+			_, width, height := layoutText(fontSize)
+			return width <= boxWidth && height <= boxHeight
+		}))
+	}
+
+	// What are the dimensions of the text.
+	runs, textWidth, textHeight := layoutText(int(displayText.FontSize))
+	displayText.Width = textWidth
+	displayText.Height = textHeight
+
+	// What is the center point of the screen?
+	centerX := int(config.ScreenWidth) / 2
+	centerY := int(config.ScreenHeight) / 2
+
+	// If we move back by half width and height we get centered text.
+	centeredX := centerX - textWidth/2
+	centeredY := centerY - textHeight/2
+
+	// Set the position of this text using the offsets.
+	displayText.X = centeredX + textProperties.OffsetX
+	displayText.Y = centeredY + textProperties.OffsetY
+
+	// Place each line of text.
+	for _, run := range runs {
+		run.X += displayText.X
+		run.Y += displayText.Y
+		displayText.Runs = append(displayText.Runs, run)
+	}
+
+	return displayText
+}
+
+// fontDescription is the pango font for a font face and size.
+func fontDescription(fontFace string, fontSize uint) (description *pango.FontDescription) {
+	return pango.FontDescriptionFromString(fontFace + " " + strconv.Itoa(int(fontSize)))
+}
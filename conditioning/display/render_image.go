@@ -0,0 +1,22 @@
+package display
+
+import (
+	"github.com/gotk3/gotk3/cairo"
+	"github.com/gotk3/gotk3/gtk"
+
+	"glemzurg/conditioning"
+)
+
+// RenderImage draws an image to the screen.
+func RenderImage(config conditioning.Config, cr *cairo.Context, displayImage conditioning.DisplayImage) {
+
+	// Only images loaded here can be drawn.
+	image, ok := displayImage.Image.(pixbufImage)
+	if !ok {
+		return
+	}
+
+	// Paint the graphic.
+	gtk.GdkCairoSetSourcePixBuf(cr, image.pixbuf, displayImage.X, displayImage.Y)
+	cr.Paint()
+}
@@ -0,0 +1,105 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotk3/gotk3/cairo"
+	"github.com/gotk3/gotk3/pango"
+
+	"glemzurg/conditioning"
+)
+
+const (
+	// The paused indicator, in screen units.
+	_PAUSED_BAR_WIDTH  = 0.015 // Each bar as a fraction of the screen width.
+	_PAUSED_BAR_HEIGHT = 0.06  // Each bar as a fraction of the screen height.
+	_PAUSED_MARGIN     = 0.03  // The gap to the top right corner as a fraction of the screen height.
+
+	// The speed overlay sits this many font sizes up from the bottom of the screen.
+	_SPEED_FONT_SIZES_UP = 2
+
+	// The problems banner.
+	_BANNER_FONT_SCALE = 0.5  // The font size as a fraction of the configured font size.
+	_BANNER_MARGIN     = 0.01 // The space around the text as a fraction of the screen width.
+)
+
+// RenderPausedIndicator draws a pause symbol in the top right corner of the letterboxed screen.
+func RenderPausedIndicator(config conditioning.Config, cr *cairo.Context, width, height int) {
+
+	// Draw in the letterboxed screen.
+	cr.Save()
+	defer cr.Restore()
+	cr.Transform(letterbox(config, width, height))
+
+	// Size the bars from the screen.
+	screenWidth, screenHeight := float64(config.ScreenWidth), float64(config.ScreenHeight)
+	barWidth := screenWidth * _PAUSED_BAR_WIDTH
+	barHeight := screenHeight * _PAUSED_BAR_HEIGHT
+	margin := screenHeight * _PAUSED_MARGIN
+
+	// Two bars, one bar width apart.
+	right := screenWidth - margin
+	cr.Rectangle(right-barWidth*3, margin, barWidth, barHeight)
+	cr.Rectangle(right-barWidth, margin, barWidth, barHeight)
+
+	// White, outlined in black to show on any slide.
+	cr.SetSourceRGBA(1, 1, 1, 0.8)
+	cr.FillPreserve()
+	cr.SetSourceRGBA(0, 0, 0, 0.8)
+	cr.SetLineWidth(barWidth / 4)
+	cr.Stroke()
+}
+
+// RenderSpeedOverlay shows the slide show speed, and how long a slide shows at it, at the bottom of the letterboxed screen.
+func RenderSpeedOverlay(config conditioning.Config, cr *cairo.Context, width, height int, percent int, interval time.Duration) {
+
+	// Draw in the letterboxed screen.
+	cr.Save()
+	defer cr.Restore()
+	cr.Transform(letterbox(config, width, height))
+
+	// White outlined text, near the bottom.
+	text := fmt.Sprintf("Speed %d%%, %v a slide", percent, interval.Round(100*time.Millisecond))
+	displayText := PrepareText(config, cr, text, conditioning.TextProperties{
+		Color:        conditioning.WHITE,
+		OffsetY:      int(config.ScreenHeight)/2 - _SPEED_FONT_SIZES_UP*int(config.FontSize),
+		OutlineColor: conditioning.BLACK,
+		Opacity:      1,
+	})
+	RenderAffirmation(config, cr, displayText)
+}
+
+// RenderProblems shows what went wrong loading the affirmations as a banner across the top of the letterboxed screen.
+func RenderProblems(config conditioning.Config, cr *cairo.Context, width, height int, problems []string) {
+
+	// Draw in the letterboxed screen.
+	cr.Save()
+	defer cr.Restore()
+	cr.Transform(letterbox(config, width, height))
+
+	screenWidth := float64(config.ScreenWidth)
+	margin := screenWidth * _BANNER_MARGIN
+
+	// Plain text, wrapped to the screen. Problems quote the file, so no markup.
+	fontSize := int(float64(config.FontSize) * _BANNER_FONT_SCALE)
+	layout := pango.CairoCreateLayout(cr)
+	layout.SetFontDescription(pango.FontDescriptionFromString(config.FontFace + " " + strconv.Itoa(fontSize)))
+	layout.SetWidth(int(screenWidth-2*margin) * pango.PANGO_SCALE)
+	layout.SetWrap(pango.WRAP_WORD_CHAR)
+	layout.SetText(strings.Join(problems, "\n"), -1)
+	_, pangoHeight := layout.GetSize()
+	textHeight := float64(pangoHeight / pango.PANGO_SCALE)
+
+	// A dark red strip behind the text.
+	cr.SetSourceRGBA(0.5, 0, 0, 0.85)
+	cr.Rectangle(0, 0, screenWidth, textHeight+2*margin)
+	cr.Fill()
+
+	// White text.
+	cr.SetSourceRGB(1, 1, 1)
+	cr.MoveTo(margin, margin)
+	pango.CairoShowLayout(cr, layout)
+}
@@ -0,0 +1,76 @@
+package display
+
+import (
+	"github.com/gotk3/gotk3/cairo"
+
+	"glemzurg/conditioning"
+)
+
+// RenderSlide draws a whole slide into an area of any size, letterboxing the configured screen inside it.
+func RenderSlide(config conditioning.Config, cr *cairo.Context, width, height int, displayText conditioning.DisplayText, displayImage *conditioning.DisplayImage, displayBoth bool) {
+
+	// Paint the screen black.
+	cr.SetSourceRGB(0, 0, 0)
+	cr.Rectangle(0, 0, float64(width), float64(height))
+	cr.Fill()
+
+	// Draw in the letterboxed screen.
+	cr.Save()
+	defer cr.Restore()
+	cr.Transform(letterbox(config, width, height))
+
+	// Render the affirmation.
+	if displayImage != nil {
+		RenderImage(config, cr, *displayImage)
+	}
+	if displayBoth {
+		RenderAffirmation(config, cr, displayText)
+	}
+}
+
+// RenderEnd draws the end of a slide show into an area of any size: black, with the closing message if there is one.
+func RenderEnd(config conditioning.Config, cr *cairo.Context, width, height int) {
+
+	// Paint the screen black.
+	cr.SetSourceRGB(0, 0, 0)
+	cr.Rectangle(0, 0, float64(width), float64(height))
+	cr.Fill()
+
+	// Is there anything to say?
+	if config.EndAction != conditioning.END_ACTION_CLOSING {
+		return
+	}
+
+	// Draw in the letterboxed screen.
+	cr.Save()
+	defer cr.Restore()
+	cr.Transform(letterbox(config, width, height))
+
+	RenderAffirmation(config, cr, PrepareText(config, cr, config.ClosingMessage, conditioning.TextProperties{}))
+}
+
+// letterbox is the transform that fits the configured screen centered in an area of any size.
+func letterbox(config conditioning.Config, width, height int) (matrix *cairo.Matrix) {
+
+	// Pick the shortest ratio.
+	widthRatio := float64(width) / float64(config.ScreenWidth)
+	heightRatio := float64(height) / float64(config.ScreenHeight)
+	ratio := widthRatio
+	if heightRatio < widthRatio {
+		ratio = heightRatio
+	}
+
+	// Pick the offset that matters.
+	var xOffset, yOffset float64
+	switch {
+	case widthRatio < heightRatio:
+		// We're centering on the y axis.
+		yOffset = (float64(height) - float64(config.ScreenHeight)*ratio) / 2.0
+	case heightRatio < widthRatio:
+		// We're centering on the x axis.
+		xOffset = (float64(width) - float64(config.ScreenWidth)*ratio) / 2.0
+	}
+
+	// Create a matrix that represents this transform.
+	return cairo.NewMatrix(ratio, 0.0, 0.0, ratio, xOffset, yOffset)
+}
@@ -0,0 +1,156 @@
+package display
+
+import (
+	"math"
+
+	"github.com/gotk3/gotk3/cairo"
+	"github.com/gotk3/gotk3/pango"
+
+	"glemzurg/conditioning"
+)
+
+// RenderAffirmation writes text to the screen.
+func RenderAffirmation(config conditioning.Config, cr *cairo.Context, displayText conditioning.DisplayText) {
+
+	// The panel sits behind everything.
+	if displayText.Panel.Color != "" {
+		renderPanel(cr, displayText)
+	}
+
+	// See-through text is drawn solid off to the side, then laid down all at once so the outline doesn't show through.
+	if displayText.Opacity < 1 {
+		cr.PushGroup()
+	}
+
+	// The shadow and glow sit behind all the lines of text.
+	if displayText.Shadow.Color != "" {
+		shadowColor, _ := conditioning.ParseColor(displayText.Shadow.Color) // Validated with the config and affirmations.
+		for _, run := range displayText.Runs {
+			renderBlurred(cr, run.X+displayText.Shadow.OffsetX, run.Y+displayText.Shadow.OffsetY, run.PangoMarkup, displayText, shadowColor, float64(displayText.Shadow.Blur))
+		}
+	}
+	if displayText.Glow.Color != "" {
+		glowColor, _ := conditioning.ParseColor(displayText.Glow.Color) // Validated with the config and affirmations.
+		for _, run := range displayText.Runs {
+			renderBlurred(cr, run.X, run.Y, run.PangoMarkup, displayText, glowColor, float64(displayText.Glow.Radius))
+		}
+	}
+
+	for _, run := range displayText.Runs {
+		renderAffirmation(cr, run.X, run.Y, run.PangoMarkup, displayText)
+	}
+
+	if displayText.Opacity < 1 {
+		cr.PopGroupToSource()
+		cr.PaintWithAlpha(displayText.Opacity)
+	}
+}
+
+// renderAffirmation writes text to the screen.
+func renderAffirmation(cr *cairo.Context, x, y int, pangoMarkup string, displayText conditioning.DisplayText) {
+
+	// If outline, draw it first.
+	if displayText.Outline {
+
+		// Create a pango layout.
+		layout := pango.CairoCreateLayout(cr)
+
+		// Position at the beginning of the text.
+		cr.MoveTo(float64(x), float64(y))
+
+		// The outline color.
+		setSourceColor(cr, displayText.OutlineColor)
+
+		// Set the font description.
+		layout.SetFontDescription(fontDescription(displayText.FontFace, displayText.FontSize))
+
+		// Set the markup in the mask.
+		layout.SetMarkup(pangoMarkup, -1)
+
+		// Half of this stroke will be the outline.
+		strokeWidth := float64(displayText.FontSize) * displayText.OutlineScale
+		cr.SetLineWidth(strokeWidth)
+
+		// Create the mask and outline the text.
+		pango.CairoLayoutPath(cr, layout)
+		cr.Stroke()
+	}
+
+	// Create a pango layout.
+	layout := pango.CairoCreateLayout(cr)
+
+	// Position at the beginning of the text.
+	cr.MoveTo(float64(x), float64(y))
+
+	// The text color.
+	setSourceColor(cr, displayText.Color)
+
+	// Set the font description.
+	layout.SetFontDescription(fontDescription(displayText.FontFace, displayText.FontSize))
+
+	// Set the markup in the mask.
+	layout.SetMarkup(pangoMarkup, -1)
+
+	// Create the mask and fill the text.
+	pango.CairoShowLayout(cr, layout)
+	cr.Fill()
+}
+
+// renderPanel draws a box with rounded corners behind all the text.
+func renderPanel(cr *cairo.Context, displayText conditioning.DisplayText) {
+	panelColor, _ := conditioning.ParseColor(displayText.Panel.Color) // Validated with the config and affirmations.
+
+	// The box around the text, with padding.
+	padding := float64(displayText.Panel.Padding)
+	x := float64(displayText.X) - padding
+	y := float64(displayText.Y) - padding
+	width := float64(displayText.Width) + 2*padding
+	height := float64(displayText.Height) + 2*padding
+	radius := cornerRadius(float64(displayText.Panel.Radius), width, height)
+
+	// Go around the corners clockwise from the top right.
+	cr.NewPath()
+	cr.Arc(x+width-radius, y+radius, radius, -math.Pi/2, 0)
+	cr.Arc(x+width-radius, y+height-radius, radius, 0, math.Pi/2)
+	cr.Arc(x+radius, y+height-radius, radius, math.Pi/2, math.Pi)
+	cr.Arc(x+radius, y+radius, radius, math.Pi, 3*math.Pi/2)
+	cr.ClosePath()
+
+	setSourceColor(cr, panelColor)
+	cr.Fill()
+}
+
+// renderBlurred draws text with soft edges spreading out to a radius, for shadows and glows.
+func renderBlurred(cr *cairo.Context, x, y int, pangoMarkup string, displayText conditioning.DisplayText, color conditioning.Color, radius float64) {
+	cr.Save()
+	defer cr.Restore()
+
+	// Create a pango layout.
+	layout := pango.CairoCreateLayout(cr)
+
+	// Set the font description.
+	layout.SetFontDescription(fontDescription(displayText.FontFace, displayText.FontSize))
+
+	// Set the markup in the mask.
+	layout.SetMarkup(pangoMarkup, -1)
+
+	// Create the mask once, then fill and stroke it in layers.
+	cr.MoveTo(float64(x), float64(y))
+	pango.CairoLayoutPath(cr, layout)
+	cr.SetLineJoin(cairo.LINE_JOIN_ROUND)
+	for _, layer := range blurLayers(radius, color.Alpha) {
+		cr.SetSourceRGBA(color.Red, color.Green, color.Blue, layer.alpha)
+		if layer.lineWidth == 0 {
+			cr.FillPreserve()
+		} else {
+			cr.SetLineWidth(layer.lineWidth)
+			cr.StrokePreserve()
+		}
+	}
+	cr.NewPath()
+}
+
+// setSourceColor draws in a color from here on.
+func setSourceColor(cr *cairo.Context, color conditioning.Color) {
+	cr.SetSourceRGBA(color.Red, color.Green, color.Blue, color.Alpha)
+}
@@ -0,0 +1,37 @@
+package display
+
+import (
+	"math"
+)
+
+const (
+	// The most see-through strokes that make up a blur.
+	_BLUR_MAX_LAYERS = 8
+)
+
+// blurLayer is one of the see-through strokes that make up a blur.
+type blurLayer struct {
+	lineWidth float64 // How wide the stroke is, 0 to fill the text.
+	alpha     float64 // How solid the stroke is.
+}
+
+// blurLayers are the strokes that soften the edges of text out to a radius, each wider and fainter towards the edge.
+// Where they all overlap, on the text itself, they add up to the alpha.
+func blurLayers(radius, alpha float64) (layers []blurLayer) {
+	if radius <= 0 {
+		return []blurLayer{{lineWidth: 0, alpha: alpha}}
+	}
+	strokes := int(math.Min(math.Ceil(radius), _BLUR_MAX_LAYERS))
+	layerAlpha := 1 - math.Pow(1-alpha, 1/float64(strokes+1))
+	layers = append(layers, blurLayer{lineWidth: 0, alpha: layerAlpha})
+	for i := 1; i <= strokes; i++ {
+		// Half of the stroke reaches out from the edge of the text.
+		layers = append(layers, blurLayer{lineWidth: 2 * radius * float64(i) / float64(strokes), alpha: layerAlpha})
+	}
+	return layers
+}
+
+// cornerRadius keeps the corners of a box from being rounder than the box.
+func cornerRadius(radius, width, height float64) (fitted float64) {
+	return math.Max(0, math.Min(radius, math.Min(width, height)/2))
+}
@@ -0,0 +1,43 @@
+package display
+
+import (
+	"math"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type TextEffectsSuite struct{}
+
+var _ = Suite(&TextEffectsSuite{})
+
+// Add the tests.
+
+func (s *TextEffectsSuite) Test_BlurLayers(c *C) {
+
+	// No blur is just the text.
+	c.Check(blurLayers(0, 0.5), DeepEquals, []blurLayer{{lineWidth: 0, alpha: 0.5}})
+
+	// Small blurs step a pixel at a time, large blurs are limited.
+	c.Check(len(blurLayers(3, 0.5)), Equals, 4)
+	c.Check(len(blurLayers(2.5, 0.5)), Equals, 4)
+	c.Check(len(blurLayers(100, 0.5)), Equals, _BLUR_MAX_LAYERS+1)
+
+	// Wider and wider out to the radius, all together as solid as asked.
+	layers := blurLayers(3, 0.5)
+	c.Check(layers[0].lineWidth, Equals, 0.0)
+	c.Check(layers[1].lineWidth, Equals, 2.0)
+	c.Check(layers[3].lineWidth, Equals, 6.0)
+	clear := 1.0
+	for _, layer := range layers {
+		c.Check(layer.alpha, Equals, layers[0].alpha)
+		clear *= 1 - layer.alpha
+	}
+	c.Check(math.Abs((1-clear)-0.5) < 1e-9, Equals, true)
+}
+
+func (s *TextEffectsSuite) Test_CornerRadius(c *C) {
+	c.Check(cornerRadius(12, 100, 50), Equals, 12.0)
+	c.Check(cornerRadius(40, 100, 50), Equals, 25.0)
+	c.Check(cornerRadius(-1, 100, 50), Equals, 0.0)
+}
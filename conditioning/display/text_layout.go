@@ -0,0 +1,356 @@
+package display
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"glemzurg/conditioning"
+)
+
+// layoutRuns lays out markup into lines, using measure to find the size of any markup.
+// Text that doesn't wrap or break is a single run.
+// Each word is measured once, a line being as wide as its words and the spaces between them.
+// A word too wide for a line is broken between its characters.
+// Lines are placed here rather than by pango, as gotk3 has no way to align or justify pango's lines, or to read them back.
+func layoutRuns(markup string, textLayout conditioning.TextLayout, measure func(markup string) (width, height int)) (runs []conditioning.TextRun, width, height int) {
+
+	// Nothing to wrap.
+	maxWidth := int(textLayout.MaxWidth)
+	if maxWidth == 0 {
+		if !strings.Contains(markup, "\n") {
+			width, height = measure(markup)
+			return []conditioning.TextRun{{PangoMarkup: markup}}, width, height
+		}
+		maxWidth = math.MaxInt32 // Only the line breaks.
+	}
+
+	// Break each paragraph into lines.
+	type line struct {
+		words      []string // The words on the line.
+		wordWidths []int    // The width of each word.
+		width      int      // The width of the line.
+		height     int      // The height of the line.
+		last       bool     // If true, the last line of a paragraph.
+	}
+	var lines []line
+	spaceWidth, spaceHeight := measure(" ")
+	for _, paragraph := range splitMarkup(markup) {
+
+		// Measure the words, breaking any too wide for a line.
+		var words []string
+		var wordWidths, wordHeights []int
+		for _, word := range paragraph {
+			wordWidth, wordHeight := measure(word)
+			if wordWidth > maxWidth {
+				for _, piece := range breakWord(word, maxWidth, func(markup string) (width int) {
+					width, _ = measure(markup)
+					return width
+				}) {
+					wordWidth, wordHeight = measure(piece)
+					words = append(words, piece)
+					wordWidths = append(wordWidths, wordWidth)
+					wordHeights = append(wordHeights, wordHeight)
+				}
+				continue
+			}
+			words = append(words, word)
+			wordWidths = append(wordWidths, wordWidth)
+			wordHeights = append(wordHeights, wordHeight)
+		}
+		paragraph = words
+
+		// Fill the lines, each as tall as its tallest word.
+		lengths := wrapWords(wordWidths, spaceWidth, maxWidth)
+		for i, length := range lengths {
+			line := line{words: paragraph[:length], wordWidths: wordWidths[:length], height: spaceHeight, last: i == len(lengths)-1}
+			for _, wordHeight := range wordHeights[:length] {
+				if wordHeight > line.height {
+					line.height = wordHeight
+				}
+			}
+			paragraph, wordWidths, wordHeights = paragraph[length:], wordWidths[length:], wordHeights[length:]
+			line.width = lineWidth(line.wordWidths, spaceWidth)
+			lines = append(lines, line)
+			if line.width > width {
+				width = line.width
+			}
+		}
+	}
+
+	// Line up each line within the widest.
+	for _, line := range lines {
+		if textLayout.Align == conditioning.TEXT_ALIGN_JUSTIFY && !line.last && len(line.words) > 1 {
+			// Each word placed on its own, spreading out the spaces.
+			for i, x := range justifyWords(line.wordWidths, width) {
+				runs = append(runs, conditioning.TextRun{X: x, Y: height, PangoMarkup: line.words[i]})
+			}
+		} else {
+			runs = append(runs, conditioning.TextRun{X: alignLine(line.width, width, textLayout.Align), Y: height, PangoMarkup: strings.Join(line.words, " ")})
+		}
+		height += line.height
+	}
+
+	return runs, width, height
+}
+
+// splitMarkup splits pango markup into paragraphs of words, on spaces and newlines outside of tags.
+// Any unicode space breaks words, except the ones meant to hold words together.
+// Each word is markup of its own, opening and closing the tags around it.
+func splitMarkup(markup string) (paragraphs [][]string) {
+	var words []string
+	var open []string // The open tags, outermost first.
+	var word strings.Builder
+	started, hasText := false, false
+
+	// Add to the current word, opening the tags it sits inside.
+	add := func(text string, isText bool) {
+		if !started {
+			for _, tag := range open {
+				word.WriteString(tag)
+			}
+			started = true
+		}
+		word.WriteString(text)
+		hasText = hasText || isText
+	}
+
+	// Finish the current word, closing the tags it sits inside.
+	endWord := func() {
+		if hasText {
+			for i := len(open) - 1; i >= 0; i-- {
+				word.WriteString("</" + tagName(open[i]) + ">")
+			}
+			words = append(words, word.String())
+		}
+		word.Reset()
+		started, hasText = false, false
+	}
+
+	for i := 0; i < len(markup); i++ {
+		switch markup[i] {
+
+		case '<':
+			end := strings.IndexByte(markup[i:], '>')
+			if end < 0 {
+				add(markup[i:], true) // Not a tag, let pango complain.
+				i = len(markup)
+				break
+			}
+			tag := markup[i : i+end+1]
+			add(tag, false)
+			switch {
+			case strings.HasPrefix(tag, "</"):
+				if len(open) > 0 {
+					open = open[:len(open)-1]
+				}
+			case strings.HasSuffix(tag, "/>"):
+				// Opens and closes itself.
+			default:
+				open = append(open, tag)
+			}
+			i += end
+
+		case '\n':
+			endWord()
+			paragraphs = append(paragraphs, words)
+			words = nil
+
+		default:
+			r, size := utf8.DecodeRuneInString(markup[i:])
+			if breaksWords(r) {
+				endWord()
+			} else {
+				add(markup[i:i+size], true)
+			}
+			i += size - 1
+		}
+	}
+	endWord()
+
+	return append(paragraphs, words)
+}
+
+// breaksWords is true for a space that words can wrap at.
+func breaksWords(r rune) (breaks bool) {
+	switch r {
+	case '\u00a0', '\u2007', '\u202f': // No-break spaces.
+		return false
+	}
+	return unicode.IsSpace(r)
+}
+
+// tagName is the name of an opening tag, like "span" from "<span size='large'>".
+func tagName(tag string) (name string) {
+	name = strings.TrimSuffix(strings.TrimPrefix(tag, "<"), ">")
+	if space := strings.IndexAny(name, " \t\n"); space >= 0 {
+		name = name[:space]
+	}
+	return name
+}
+
+// wrapWords fills lines with as many words as fit in the width, giving how many words are on each line.
+// A word too wide for any line gets a line of its own. No words are a single empty line.
+func wrapWords(wordWidths []int, spaceWidth, maxWidth int) (lengths []int) {
+	length, width := 0, 0
+	for _, wordWidth := range wordWidths {
+		if length > 0 && width+spaceWidth+wordWidth > maxWidth {
+			lengths = append(lengths, length)
+			length, width = 0, 0
+		}
+		if length > 0 {
+			width += spaceWidth
+		}
+		width += wordWidth
+		length++
+	}
+	return append(lengths, length)
+}
+
+// lineWidth is how wide words are with a space between each.
+func lineWidth(wordWidths []int, spaceWidth int) (width int) {
+	for i, wordWidth := range wordWidths {
+		if i > 0 {
+			width += spaceWidth
+		}
+		width += wordWidth
+	}
+	return width
+}
+
+// breakWord breaks markup for a word too wide for a line into pieces that fit, as many characters on each as will go.
+// Each piece has at least one character, even if that one character is too wide.
+func breakWord(word string, maxWidth int, measureWidth func(markup string) (width int)) (pieces []string) {
+	for {
+		ends := characterEnds(word)
+		if len(ends) <= 1 || measureWidth(word) <= maxWidth {
+			return append(pieces, word)
+		}
+
+		// Search for the most characters that fit, leaving at least one for the rest.
+		low, high := 1, len(ends)-1
+		for low < high {
+			middle := (low + high + 1) / 2
+			if head, _ := cutMarkup(word, ends[middle-1]); measureWidth(head) <= maxWidth {
+				low = middle
+			} else {
+				high = middle - 1
+			}
+		}
+
+		head, tail := cutMarkup(word, ends[low-1])
+		pieces = append(pieces, head)
+		word = tail
+	}
+}
+
+// characterEnds is where each character of text in markup ends, outside of tags. An entity like "&amp;" is one character.
+func characterEnds(markup string) (ends []int) {
+	for i := 0; i < len(markup); {
+		switch markup[i] {
+		case '<':
+			end := strings.IndexByte(markup[i:], '>')
+			if end < 0 {
+				end = len(markup) - i - 1 // Not a tag, let pango complain.
+			}
+			i += end + 1
+			continue
+		case '&':
+			if end := strings.IndexByte(markup[i:], ';'); end >= 0 {
+				i += end + 1
+				ends = append(ends, i)
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(markup[i:])
+		i += size
+		ends = append(ends, i)
+	}
+	return ends
+}
+
+// cutMarkup cuts markup in two, closing the tags open at the cut and opening them again after it.
+func cutMarkup(markup string, at int) (head, tail string) {
+
+	// The tags open at the cut, outermost first.
+	var open []string
+	for i := 0; i < at; i++ {
+		if markup[i] != '<' {
+			continue
+		}
+		end := strings.IndexByte(markup[i:at], '>')
+		if end < 0 {
+			break
+		}
+		tag := markup[i : i+end+1]
+		switch {
+		case strings.HasPrefix(tag, "</"):
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case strings.HasSuffix(tag, "/>"):
+			// Opens and closes itself.
+		default:
+			open = append(open, tag)
+		}
+		i += end
+	}
+
+	head = markup[:at]
+	for i := len(open) - 1; i >= 0; i-- {
+		head += "</" + tagName(open[i]) + ">"
+	}
+	return head, strings.Join(open, "") + markup[at:]
+}
+
+// alignLine is where a line starts within a wider block of text.
+func alignLine(lineWidth, blockWidth int, align conditioning.TextAlign) (x int) {
+	switch align {
+	case conditioning.TEXT_ALIGN_LEFT, conditioning.TEXT_ALIGN_JUSTIFY:
+		return 0
+	case conditioning.TEXT_ALIGN_RIGHT:
+		return blockWidth - lineWidth
+	}
+	return (blockWidth - lineWidth) / 2
+}
+
+// justifyWords is where each word starts to stretch a line from one side of the block to the other.
+func justifyWords(wordWidths []int, blockWidth int) (xs []int) {
+	if len(wordWidths) == 1 {
+		return []int{0}
+	}
+	total := 0
+	for _, wordWidth := range wordWidths {
+		total += wordWidth
+	}
+	gaps := len(wordWidths) - 1
+	space := blockWidth - total
+	x := 0
+	for i, wordWidth := range wordWidths {
+		xs = append(xs, x)
+		// Share the space out evenly, the rounding spread across the gaps.
+		x += wordWidth + space*(i+1)/gaps - space*i/gaps
+	}
+	return xs
+}
+
+// fitFontSize finds the largest font size, no larger than the font size given, that fits.
+// If nothing fits, the smallest font size.
+func fitFontSize(fontSize int, fits func(fontSize int) bool) (fitted int) {
+	if fits(fontSize) {
+		return fontSize
+	}
+
+	// Search between a size that fits and one that doesn't.
+	low, high := 1, fontSize
+	for high-low > 1 {
+		middle := (low + high) / 2
+		if fits(middle) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return low
+}
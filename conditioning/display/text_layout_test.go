@@ -0,0 +1,202 @@
+package display
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+
+	"glemzurg/conditioning"
+)
+
+// Create a suite.
+type TextLayoutSuite struct{}
+
+var _ = Suite(&TextLayoutSuite{})
+
+// Add the tests.
+
+func (s *TextLayoutSuite) Test_SplitMarkup(c *C) {
+	tests := []struct {
+		markup     string
+		paragraphs [][]string
+	}{
+		{`<span></span>`, [][]string{nil}},
+		{`<span>one</span>`, [][]string{{`<span>one</span>`}}},
+		{`<span>one  two</span>`, [][]string{{`<span>one</span>`, `<span>two</span>`}}},
+		{`<span>one <i>two three</i> four</span>`, [][]string{{`<span>one</span>`, `<span><i>two</i></span>`, `<span><i>three</i></span>`, `<span>four</span>`}}},
+		{`<span>o<b>n</b>e</span>`, [][]string{{`<span>o<b>n</b>e</span>`}}},
+		{`<span size='large'>one two</span>`, [][]string{{`<span size='large'>one</span>`, `<span size='large'>two</span>`}}},
+		{`<span>one<br/>two</span>`, [][]string{{`<span>one<br/>two</span>`}}},
+		{`<span>one &amp; two</span>`, [][]string{{`<span>one</span>`, `<span>&amp;</span>`, `<span>two</span>`}}},
+		{`<span>one</span> <i></i> `, [][]string{{`<span>one</span>`}}},
+		{"<span>one\ntwo three\n\nfour</span>", [][]string{{`<span>one</span>`}, {`<span>two</span>`, `<span>three</span>`}, nil, {`<span>four</span>`}}},
+		{"<span>one\ttwo\u3000three</span>", [][]string{{`<span>one</span>`, `<span>two</span>`, `<span>three</span>`}}},
+		{"<span>10\u00a0km é</span>", [][]string{{"<span>10\u00a0km</span>", `<span>é</span>`}}},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(splitMarkup(test.markup), DeepEquals, test.paragraphs, comment)
+	}
+}
+
+func (s *TextLayoutSuite) Test_WrapWords(c *C) {
+	tests := []struct {
+		wordWidths []int
+		maxWidth   int
+		lengths    []int
+	}{
+		{nil, 100, []int{0}},
+		{[]int{30, 30, 50}, 130, []int{3}},
+		{[]int{30, 30, 50}, 70, []int{2, 1}},
+		{[]int{30, 30, 50}, 30, []int{1, 1, 1}},
+		{[]int{200, 30}, 100, []int{1, 1}},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(wrapWords(test.wordWidths, 10, test.maxWidth), DeepEquals, test.lengths, comment)
+	}
+}
+
+func (s *TextLayoutSuite) Test_LineWidth(c *C) {
+	c.Check(lineWidth(nil, 10), Equals, 0)
+	c.Check(lineWidth([]int{30}, 10), Equals, 30)
+	c.Check(lineWidth([]int{30, 30, 50}, 10), Equals, 130)
+}
+
+func (s *TextLayoutSuite) Test_BreakWord(c *C) {
+	tests := []struct {
+		word     string
+		maxWidth int
+		pieces   []string
+	}{
+		{`abc`, 30, []string{`abc`}},
+		{`abcdef`, 25, []string{`ab`, `cd`, `ef`}},
+		{`abcde`, 30, []string{`abc`, `de`}},
+		{`abc`, 5, []string{`a`, `b`, `c`}},
+		{`<span><b>abc</b>de</span>`, 20, []string{`<span><b>ab</b></span>`, `<span><b>c</b>d</span>`, `<span>e</span>`}},
+		{`<span>a<br/>bc</span>`, 20, []string{`<span>a<br/>b</span>`, `<span>c</span>`}},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(breakWord(test.word, test.maxWidth, measureWidthForTest), DeepEquals, test.pieces, comment)
+	}
+}
+
+func (s *TextLayoutSuite) Test_CharacterEnds(c *C) {
+	c.Check(characterEnds(``), IsNil)
+	c.Check(characterEnds(`ab`), DeepEquals, []int{1, 2})
+	c.Check(characterEnds(`<b>a</b>é`), DeepEquals, []int{4, 10})
+	c.Check(characterEnds(`a&amp;b`), DeepEquals, []int{1, 6, 7})
+}
+
+func (s *TextLayoutSuite) Test_CutMarkup(c *C) {
+	head, tail := cutMarkup(`<span><i>ab</i>cd</span>`, 10)
+	c.Check(head, Equals, `<span><i>a</i></span>`)
+	c.Check(tail, Equals, `<span><i>b</i>cd</span>`)
+	head, tail = cutMarkup(`<span><i>ab</i>cd</span>`, 16)
+	c.Check(head, Equals, `<span><i>ab</i>c</span>`)
+	c.Check(tail, Equals, `<span>d</span>`)
+}
+
+func (s *TextLayoutSuite) Test_AlignLine(c *C) {
+	c.Check(alignLine(40, 100, ""), Equals, 30)
+	c.Check(alignLine(40, 100, conditioning.TEXT_ALIGN_CENTER), Equals, 30)
+	c.Check(alignLine(40, 100, conditioning.TEXT_ALIGN_LEFT), Equals, 0)
+	c.Check(alignLine(40, 100, conditioning.TEXT_ALIGN_RIGHT), Equals, 60)
+	c.Check(alignLine(40, 100, conditioning.TEXT_ALIGN_JUSTIFY), Equals, 0)
+}
+
+func (s *TextLayoutSuite) Test_JustifyWords(c *C) {
+	c.Check(justifyWords([]int{30}, 100), DeepEquals, []int{0})
+	c.Check(justifyWords([]int{30, 30}, 100), DeepEquals, []int{0, 70})
+	c.Check(justifyWords([]int{20, 20, 20}, 100), DeepEquals, []int{0, 40, 80})
+	c.Check(justifyWords([]int{20, 20, 20, 20}, 100), DeepEquals, []int{0, 26, 53, 80}) // 20 spread over 3 gaps.
+}
+
+func (s *TextLayoutSuite) Test_FitFontSize(c *C) {
+	fitsUnder := func(limit int) func(fontSize int) bool {
+		return func(fontSize int) bool { return fontSize <= limit }
+	}
+	c.Check(fitFontSize(24, fitsUnder(100)), Equals, 24)
+	c.Check(fitFontSize(24, fitsUnder(24)), Equals, 24)
+	c.Check(fitFontSize(24, fitsUnder(17)), Equals, 17)
+	c.Check(fitFontSize(24, fitsUnder(1)), Equals, 1)
+	c.Check(fitFontSize(24, fitsUnder(0)), Equals, 1)
+}
+
+func (s *TextLayoutSuite) Test_LayoutRuns(c *C) {
+	markup := `<span>aa bb cc dd ee</span>`
+
+	// No wrapping.
+	runs, width, height := layoutRuns(markup, conditioning.TextLayout{}, measureForTest)
+	c.Check(runs, DeepEquals, []conditioning.TextRun{{PangoMarkup: markup}})
+	c.Check(width, Equals, 140)
+	c.Check(height, Equals, 20)
+
+	// Line breaks without wrapping.
+	runs, width, height = layoutRuns("<span>aa bb\ncc</span>", conditioning.TextLayout{}, measureForTest)
+	c.Check(runs, DeepEquals, []conditioning.TextRun{
+		{X: 0, Y: 0, PangoMarkup: `<span>aa</span> <span>bb</span>`},
+		{X: 15, Y: 20, PangoMarkup: `<span>cc</span>`},
+	})
+	c.Check(width, Equals, 50)
+	c.Check(height, Equals, 40)
+
+	// Centered.
+	runs, width, height = layoutRuns(markup, conditioning.TextLayout{MaxWidth: 80}, measureForTest)
+	c.Check(runs, DeepEquals, []conditioning.TextRun{
+		{X: 0, Y: 0, PangoMarkup: `<span>aa</span> <span>bb</span> <span>cc</span>`},
+		{X: 15, Y: 20, PangoMarkup: `<span>dd</span> <span>ee</span>`},
+	})
+	c.Check(width, Equals, 80)
+	c.Check(height, Equals, 40)
+
+	// Right.
+	runs, _, _ = layoutRuns(markup, conditioning.TextLayout{MaxWidth: 80, Align: conditioning.TEXT_ALIGN_RIGHT}, measureForTest)
+	c.Check(runs[1].X, Equals, 30)
+
+	// Justified, except the last line.
+	runs, width, height = layoutRuns(`<span>aaa bb cc d ee ff</span>`, conditioning.TextLayout{MaxWidth: 80, Align: conditioning.TEXT_ALIGN_JUSTIFY}, measureForTest)
+	c.Check(runs, DeepEquals, []conditioning.TextRun{
+		{X: 0, Y: 0, PangoMarkup: `<span>aaa</span>`},
+		{X: 50, Y: 0, PangoMarkup: `<span>bb</span>`},
+		{X: 0, Y: 20, PangoMarkup: `<span>cc</span>`},
+		{X: 30, Y: 20, PangoMarkup: `<span>d</span>`},
+		{X: 50, Y: 20, PangoMarkup: `<span>ee</span>`},
+		{X: 0, Y: 40, PangoMarkup: `<span>ff</span>`},
+	})
+	c.Check(width, Equals, 70)
+	c.Check(height, Equals, 60)
+
+	// A word too wide for a line is broken.
+	runs, width, height = layoutRuns(`<span>aa bbbbbbbbb c</span>`, conditioning.TextLayout{MaxWidth: 40, Align: conditioning.TEXT_ALIGN_LEFT}, measureForTest)
+	c.Check(runs, DeepEquals, []conditioning.TextRun{
+		{X: 0, Y: 0, PangoMarkup: `<span>aa</span>`},
+		{X: 0, Y: 20, PangoMarkup: `<span>bbbb</span>`},
+		{X: 0, Y: 40, PangoMarkup: `<span>bbbb</span>`},
+		{X: 0, Y: 60, PangoMarkup: `<span>b</span> <span>c</span>`},
+	})
+	c.Check(width, Equals, 40)
+	c.Check(height, Equals, 80)
+}
+
+// measureForTest sizes markup as 10 wide for each character of text and 20 high.
+func measureForTest(markup string) (width, height int) {
+	return measureWidthForTest(markup), 20
+}
+
+// measureWidthForTest sizes markup as 10 wide for each character of text.
+func measureWidthForTest(markup string) (width int) {
+	inTag := false
+	for _, r := range strings.Split(markup, "") {
+		switch {
+		case r == "<":
+			inTag = true
+		case r == ">":
+			inTag = false
+		case !inTag:
+			width += 10
+		}
+	}
+	return width
+}
@@ -16,6 +16,14 @@ func (e *errorWithStack) Error() string {
 	return e.message + "\n\n" + e.stackDump
 }
 
+// errorMessage gets the message of an error, without any stack.
+func errorMessage(err error) (message string) {
+	if withStack, ok := err.(*errorWithStack); ok {
+		return withStack.message
+	}
+	return err.Error()
+}
+
 // Error creates an new error with stack information.
 func Error(err error) (errWithStack error) {
 
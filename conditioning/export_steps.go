@@ -0,0 +1,49 @@
+package conditioning
+
+import (
+	"time"
+)
+
+// ExportStep is one still moment of an exported slide show.
+type ExportStep struct {
+	AffirmationIndex int           // The affirmation on screen.
+	DisplayBoth      bool          // If false, display image only.
+	Duration         time.Duration // How long the step stays on screen.
+}
+
+// ExportSteps lays out one cycle of the slide show, in the order the slide show would use.
+// Slides with an image reveal the image alone first, the same as navigating with Right().
+func ExportSteps(config Config, slides []Slide, random bool) (steps []ExportStep) {
+
+	if len(slides) == 0 {
+		return nil
+	}
+
+	// Use the same ordering as a running slide show.
+	sequencer := NewOrderedSequencer()
+	cycleLength := len(slides)
+	if random {
+		sequencer = NewShuffledNoRepeatSequencer()
+		cycleLength = 0
+		for _, slide := range slides {
+			cycleLength += slide.Affirmation.weight()
+		}
+	}
+	var affirmations []Affirmation
+	for _, slide := range slides {
+		affirmations = append(affirmations, slide.Affirmation)
+	}
+	sequencer.Reset(affirmations)
+
+	affirmationIndex := -1 // Nothing on screen yet.
+	for i := 0; i < cycleLength; i++ {
+		affirmationIndex = sequencer.Next(affirmationIndex)
+		duration := affirmationDuration(config, slides[affirmationIndex].Affirmation)
+		if slides[affirmationIndex].DisplayImage != nil {
+			steps = append(steps, ExportStep{AffirmationIndex: affirmationIndex, DisplayBoth: false, Duration: duration})
+		}
+		steps = append(steps, ExportStep{AffirmationIndex: affirmationIndex, DisplayBoth: true, Duration: duration})
+	}
+
+	return steps
+}
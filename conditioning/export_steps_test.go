@@ -0,0 +1,45 @@
+package conditioning
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type ExportStepsSuite struct{}
+
+var _ = Suite(&ExportStepsSuite{})
+
+// Add the tests.
+
+func (s *ExportStepsSuite) Test_ExportSteps(c *C) {
+	config := Config{SleepMilli: 2000}
+	slides := []Slide{
+		{DisplayImage: &DisplayImage{}},
+		{Affirmation: Affirmation{Duration: 5 * time.Second}},
+		{DisplayImage: &DisplayImage{}},
+	}
+
+	// Images are revealed before their text.
+	second := 2 * time.Second
+	c.Check(ExportSteps(config, slides, false), DeepEquals, []ExportStep{
+		{AffirmationIndex: 0, DisplayBoth: false, Duration: second},
+		{AffirmationIndex: 0, DisplayBoth: true, Duration: second},
+		{AffirmationIndex: 1, DisplayBoth: true, Duration: 5 * time.Second},
+		{AffirmationIndex: 2, DisplayBoth: false, Duration: second},
+		{AffirmationIndex: 2, DisplayBoth: true, Duration: second},
+	})
+
+	// Random order still shows every slide once.
+	steps := ExportSteps(config, slides, true)
+	c.Check(len(steps), Equals, 5)
+	seen := map[int]bool{}
+	for _, step := range steps {
+		seen[step.AffirmationIndex] = true
+	}
+	c.Check(len(seen), Equals, 3)
+
+	// Nothing to show.
+	c.Check(ExportSteps(config, nil, false), IsNil)
+}
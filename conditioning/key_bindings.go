@@ -0,0 +1,226 @@
+package conditioning
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// The actions a key can be bound to.
+	ACTION_NEXT              Action = "next"              // Forward through the slide show order.
+	ACTION_PREV              Action = "prev"              // Back through the slide show order.
+	ACTION_TOGGLE_PLAY       Action = "toggle-play"       // Play or pause the slide show.
+	ACTION_STOP              Action = "stop"              // Stop the slide show.
+	ACTION_TOGGLE_RANDOM     Action = "toggle-random"     // Random or ordered slide show.
+	ACTION_TOGGLE_SPACED     Action = "toggle-spaced"     // Spaced repetition on or off.
+	ACTION_KNOWN             Action = "known"             // Grade the affirmation known.
+	ACTION_NOT_YET           Action = "not-yet"           // Grade the affirmation not known yet.
+	ACTION_RELOAD            Action = "reload"            // Load the affirmations file again.
+	ACTION_SPEED_UP          Action = "speed-up"          // Show slides for less time.
+	ACTION_SLOW_DOWN         Action = "slow-down"         // Show slides for more time.
+	ACTION_SAVE_SPEED        Action = "save-speed"        // Write the speed to the config file.
+	ACTION_TOGGLE_FULLSCREEN Action = "toggle-fullscreen" // Fullscreen or windowed.
+	ACTION_NONE              Action = "none"              // Unbind a key.
+)
+
+// Action is something a key can do.
+type Action string
+
+// The keys that work without any configuration.
+var _DEFAULT_KEY_BINDINGS = map[string]Action{
+	"Right":  ACTION_NEXT,
+	"Left":   ACTION_PREV,
+	"space":  ACTION_TOGGLE_PLAY,
+	"Escape": ACTION_STOP,
+	"r":      ACTION_TOGGLE_RANDOM,
+	"s":      ACTION_TOGGLE_SPACED,
+	"k":      ACTION_KNOWN,
+	"n":      ACTION_NOT_YET,
+	"l":      ACTION_RELOAD,
+	"plus":   ACTION_SPEED_UP,
+	"equal":  ACTION_SPEED_UP,
+	"minus":  ACTION_SLOW_DOWN,
+	"w":      ACTION_SAVE_SPEED,
+	"F11":    ACTION_TOGGLE_FULLSCREEN,
+}
+
+// The actions turned off in kiosk mode, since they change what is shown.
+var _KIOSK_DISABLED_ACTIONS = []Action{
+	ACTION_RELOAD,
+	ACTION_TOGGLE_RANDOM,
+	ACTION_TOGGLE_SPACED,
+	ACTION_SAVE_SPEED,
+}
+
+// The key values of named keys, using the GDK key names. Single characters are their own names.
+var _KEY_VALUES = map[string]uint{
+	"space":       0x0020,
+	"plus":        0x002b,
+	"comma":       0x002c,
+	"minus":       0x002d,
+	"period":      0x002e,
+	"equal":       0x003d,
+	"BackSpace":   0xff08,
+	"Tab":         0xff09,
+	"Return":      0xff0d,
+	"Escape":      0xff1b,
+	"Home":        0xff50,
+	"Left":        0xff51,
+	"Up":          0xff52,
+	"Right":       0xff53,
+	"Down":        0xff54,
+	"Page_Up":     0xff55,
+	"Prior":       0xff55,
+	"Page_Down":   0xff56,
+	"Next":        0xff56,
+	"End":         0xff57,
+	"Insert":      0xff63,
+	"KP_Enter":    0xff8d,
+	"KP_Add":      0xffab,
+	"KP_Subtract": 0xffad,
+	"F1":          0xffbe,
+	"F2":          0xffbf,
+	"F3":          0xffc0,
+	"F4":          0xffc1,
+	"F5":          0xffc2,
+	"F6":          0xffc3,
+	"F7":          0xffc4,
+	"F8":          0xffc5,
+	"F9":          0xffc6,
+	"F10":         0xffc7,
+	"F11":         0xffc8,
+	"F12":         0xffc9,
+	"Delete":      0xffff,
+}
+
+// The actions that can be bound, in the order they are described.
+var _ACTIONS = []Action{
+	ACTION_NEXT,
+	ACTION_PREV,
+	ACTION_TOGGLE_PLAY,
+	ACTION_STOP,
+	ACTION_TOGGLE_RANDOM,
+	ACTION_TOGGLE_SPACED,
+	ACTION_KNOWN,
+	ACTION_NOT_YET,
+	ACTION_RELOAD,
+	ACTION_SPEED_UP,
+	ACTION_SLOW_DOWN,
+	ACTION_SAVE_SPEED,
+	ACTION_TOGGLE_FULLSCREEN,
+	ACTION_NONE,
+}
+
+// KeyValue gets the GDK key value of a key name like "Page_Down" or "j".
+func KeyValue(keyName string) (keyValue uint, found bool) {
+	if keyValue, found = _KEY_VALUES[keyName]; found {
+		return keyValue, true
+	}
+
+	// A printable character is its own key value.
+	if len(keyName) == 1 && keyName[0] > ' ' && keyName[0] <= '~' {
+		return uint(keyName[0]), true
+	}
+
+	return 0, false
+}
+
+// KeyBindings gets the action of every bound key by key name, the configured bindings on top of the defaults.
+// A configured key replaces every default on the same key, whatever name it goes by ("+" and "plus" are one key).
+// In kiosk mode the keys that change what is shown do nothing.
+func KeyBindings(config Config) (bindings map[string]Action) {
+	bindings = map[string]Action{}
+	for keyName, action := range _DEFAULT_KEY_BINDINGS {
+		bindings[keyName] = action
+	}
+	for keyName, action := range config.KeyBindings {
+		keyValue, _ := KeyValue(keyName) // Validated with the config.
+		for defaultName := range _DEFAULT_KEY_BINDINGS {
+			if defaultValue, _ := KeyValue(defaultName); defaultValue == keyValue {
+				delete(bindings, defaultName)
+			}
+		}
+		bindings[keyName] = action
+	}
+
+	// Drop the keys that do nothing.
+	for keyName, action := range bindings {
+		if action == ACTION_NONE || (config.Kiosk && kioskDisabled(action)) {
+			delete(bindings, keyName)
+		}
+	}
+
+	return bindings
+}
+
+// kioskDisabled checks if an action is turned off in kiosk mode.
+func kioskDisabled(action Action) (disabled bool) {
+	for _, disabled := range _KIOSK_DISABLED_ACTIONS {
+		if action == disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeKeyBindings lists what every bound key does, like "Right (next), Left (prev)".
+func DescribeKeyBindings(bindings map[string]Action) (description string) {
+	var described []string
+	for _, action := range _ACTIONS {
+
+		// The keys for this action.
+		var keyNames []string
+		for keyName, bound := range bindings {
+			if bound == action {
+				keyNames = append(keyNames, keyName)
+			}
+		}
+		if len(keyNames) == 0 {
+			continue
+		}
+
+		sort.Strings(keyNames)
+		described = append(described, strings.Join(keyNames, "/")+" ("+string(action)+")")
+	}
+	return strings.Join(described, ", ")
+}
+
+// validateKeyBindings checks every configured key and action is known.
+func validateKeyBindings(keyBindings map[string]Action) (err error) {
+
+	// Check in a stable order so the same problem is always reported.
+	var keyNames []string
+	for keyName := range keyBindings {
+		keyNames = append(keyNames, keyName)
+	}
+	sort.Strings(keyNames)
+
+	keyNamesByValue := map[uint]string{}
+	for _, keyName := range keyNames {
+		action := keyBindings[keyName]
+		keyValue, found := KeyValue(keyName)
+		if !found {
+			return Errorf(`invalid KeyBindings key: '%s'`, keyName)
+		}
+		if !knownAction(action) {
+			return Errorf(`invalid KeyBindings action: '%s' (for key '%s')`, action, keyName)
+		}
+
+		// Two names for one key would leave which one wins to chance.
+		if otherName, found := keyNamesByValue[keyValue]; found {
+			return Errorf(`invalid KeyBindings key: '%s' (the same key as '%s')`, keyName, otherName)
+		}
+		keyNamesByValue[keyValue] = keyName
+	}
+	return nil
+}
+
+// knownAction checks an action is one that can be bound.
+func knownAction(action Action) (known bool) {
+	for _, known := range _ACTIONS {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
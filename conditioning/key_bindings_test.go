@@ -0,0 +1,105 @@
+package conditioning
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type KeyBindingsSuite struct{}
+
+var _ = Suite(&KeyBindingsSuite{})
+
+// Add the tests.
+
+func (s *KeyBindingsSuite) Test_KeyValue(c *C) {
+	tests := []struct {
+		keyName  string
+		keyValue uint
+		found    bool
+	}{
+		{"space", 32, true},
+		{"Page_Down", 65366, true},
+		{"Next", 65366, true},
+		{"F11", 65480, true},
+		{"j", 106, true},
+		{"J", 74, true},
+		{"?", 63, true},
+		{"", 0, false},
+		{" ", 0, false},
+		{"jk", 0, false},
+		{"PageDown", 0, false},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+
+		keyValue, found := KeyValue(test.keyName)
+		c.Check(keyValue, Equals, test.keyValue, comment)
+		c.Check(found, Equals, test.found, comment)
+	}
+}
+
+func (s *KeyBindingsSuite) Test_KeyBindings(c *C) {
+
+	// The defaults.
+	bindings := KeyBindings(Config{})
+	c.Check(bindings, DeepEquals, _DEFAULT_KEY_BINDINGS)
+
+	// Added, replaced and removed.
+	bindings = KeyBindings(Config{KeyBindings: map[string]Action{
+		"Page_Down": ACTION_NEXT,
+		"Page_Up":   ACTION_PREV,
+		"l":         ACTION_NEXT,
+		"r":         ACTION_NONE,
+		"q":         ACTION_NONE,
+	}})
+	c.Check(bindings["Page_Down"], Equals, ACTION_NEXT)
+	c.Check(bindings["Page_Up"], Equals, ACTION_PREV)
+	c.Check(bindings["l"], Equals, ACTION_NEXT)
+	_, found := bindings["r"]
+	c.Check(found, Equals, false)
+	_, found = bindings["q"]
+	c.Check(found, Equals, false)
+	c.Check(len(bindings), Equals, len(_DEFAULT_KEY_BINDINGS)+1)
+
+	// A key replaces the defaults on the same key, by any name.
+	bindings = KeyBindings(Config{KeyBindings: map[string]Action{
+		"+": ACTION_NONE,
+		"=": ACTION_SLOW_DOWN,
+	}})
+	_, found = bindings["plus"]
+	c.Check(found, Equals, false)
+	_, found = bindings["equal"]
+	c.Check(found, Equals, false)
+	c.Check(bindings["="], Equals, ACTION_SLOW_DOWN)
+	c.Check(bindings["minus"], Equals, ACTION_SLOW_DOWN)
+	c.Check(len(bindings), Equals, len(_DEFAULT_KEY_BINDINGS)-1)
+
+	// The defaults are untouched.
+	c.Check(_DEFAULT_KEY_BINDINGS["r"], Equals, ACTION_TOGGLE_RANDOM)
+
+	// Kiosk mode drops the keys that change what is shown, even configured ones.
+	bindings = KeyBindings(Config{Kiosk: true, KeyBindings: map[string]Action{
+		"Page_Down": ACTION_NEXT,
+		"F5":        ACTION_RELOAD,
+	}})
+	c.Check(bindings["Page_Down"], Equals, ACTION_NEXT)
+	c.Check(bindings["space"], Equals, ACTION_TOGGLE_PLAY)
+	c.Check(bindings["F11"], Equals, ACTION_TOGGLE_FULLSCREEN)
+	for keyName, action := range bindings {
+		c.Check(kioskDisabled(action), Equals, false, Commentf("%s: %s", keyName, action))
+	}
+	_, found = bindings["F5"]
+	c.Check(found, Equals, false)
+	_, found = bindings["l"]
+	c.Check(found, Equals, false)
+}
+
+func (s *KeyBindingsSuite) Test_DescribeKeyBindings(c *C) {
+	c.Check(DescribeKeyBindings(map[string]Action{
+		"space":     ACTION_TOGGLE_PLAY,
+		"Right":     ACTION_NEXT,
+		"Page_Down": ACTION_NEXT,
+		"h":         ACTION_PREV,
+	}), Equals, "Page_Down/Right (next), h (prev), space (toggle-play)")
+	c.Check(DescribeKeyBindings(nil), Equals, "")
+}
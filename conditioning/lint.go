@@ -0,0 +1,84 @@
+package conditioning
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// LintAffirmations checks an affirmations file for problems without displaying it.
+func LintAffirmations(preparer SlidePreparer, config Config, affirmationFilename, imagePath string) (diagnostics []ParseDiagnostic, err error) {
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Load from the text file, keeping the parse problems.
+	affirmations, _, diagnostics, err := LoadAffirmations(affirmationFilename)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	// Check each affirmation.
+	firstLines := map[string]int{}
+	for _, affirmation := range affirmations {
+
+		// A problem with this affirmation.
+		problem := func(severity DiagnosticSeverity, message, token string) {
+			diagnostics = append(diagnostics, ParseDiagnostic{
+				File:     affirmationFilename,
+				Line:     affirmation.Line,
+				Severity: severity,
+				Message:  message,
+				Token:    token,
+			})
+		}
+
+		// Has this message been seen before?
+		if firstLine, found := firstLines[affirmation.Message]; found {
+			problem(SEVERITY_WARNING, "duplicate affirmation, first on line "+strconv.Itoa(firstLine), affirmation.Message)
+		} else {
+			firstLines[affirmation.Message] = affirmation.Line
+		}
+
+		// Does the text fit on the screen?
+		displayText := preparer.PrepareText(config, affirmation.Message, affirmation.Text)
+		if !onCanvas(config, float64(displayText.X), float64(displayText.Y), displayText.Width, displayText.Height) {
+			problem(SEVERITY_WARNING, "text extends off the canvas", affirmation.Message)
+		}
+
+		// Is there an image?
+		var displayImage *DisplayImage
+		if affirmation.Image.Filename != "" {
+
+			// Does the image exist and can it be read?
+			if _, err := os.Stat(imagePath + affirmation.Image.Filename); err != nil {
+				problem(SEVERITY_ERROR, "image file not found", affirmation.Image.Filename)
+			} else if prepared, err := preparer.PrepareImage(config, imagePath, affirmation.Image); err != nil {
+				problem(SEVERITY_ERROR, "image could not be decoded", affirmation.Image.Filename)
+			} else {
+				displayImage = &prepared
+
+				// Does the image fit on the screen?
+				if !onCanvas(config, displayImage.X, displayImage.Y, displayImage.Width, displayImage.Height) {
+					problem(SEVERITY_WARNING, "image extends off the canvas", affirmation.Image.Filename)
+				}
+			}
+		}
+
+		// Does the text stand out from what is behind it?
+		background := textBackground(displayText, displayImage)
+		displayText = matchColor(config, affirmation.Text, displayText, background)
+		if contrast := outlinedContrast(displayText, background); contrast < minContrast(config) {
+			problem(SEVERITY_WARNING, fmt.Sprintf("poor text contrast against the background (%.1f:1, less than %.1f:1)", contrast, minContrast(config)), affirmation.Message)
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// onCanvas checks that a rectangle sits entirely on the configured screen.
+func onCanvas(config Config, x, y float64, width, height int) (inside bool) {
+	return x >= 0 && y >= 0 &&
+		x+float64(width) <= float64(config.ScreenWidth) &&
+		y+float64(height) <= float64(config.ScreenHeight)
+}
@@ -0,0 +1,37 @@
+package conditioning
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type LintSuite struct{}
+
+var _ = Suite(&LintSuite{})
+
+// Add the tests.
+
+func (s *LintSuite) Test_OnCanvas(c *C) {
+	config := Config{ScreenWidth: 100, ScreenHeight: 50}
+	tests := []struct {
+		x, y          float64
+		width, height int
+		inside        bool
+	}{
+		{0, 0, 100, 50, true},
+		{10, 10, 20, 20, true},
+		{-1, 0, 10, 10, false},
+		{0, -1, 10, 10, false},
+		{91, 0, 10, 10, false},
+		{0, 41, 10, 10, false},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(onCanvas(config, test.x, test.y, test.width, test.height), Equals, test.inside, comment)
+	}
+}
+
+func (s *LintSuite) Test_DiagnosticString(c *C) {
+	diagnostic := ParseDiagnostic{File: "affirmations.txt", Line: 7, Severity: SEVERITY_WARNING, Message: "text extends off the canvas", Token: "Hello"}
+	c.Check(diagnostic.String(), Equals, `affirmations.txt:7: warning: text extends off the canvas: 'Hello'`)
+}
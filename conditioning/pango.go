@@ -1,35 +1,210 @@
 package conditioning
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-// PangoMarkup
+const (
+	// The characters with a meaning of their own in a message.
+	_MARKUP_ESCAPE     = '\\' // The next character is just a character, "\n" is a line break.
+	_MARKUP_SPAN_OPEN  = '{'  // Starts a span of color or size, like "{gold:40 text}".
+	_MARKUP_SPAN_CLOSE = '}'  // Ends a span.
+
+	// Characters that can come just before markup opens, besides spaces.
+	_MARKUP_OPENS_AFTER = `([{"'“‘`
+)
+
+// The characters on each side of text that style it, and the pango tag for each.
+var _MARKUP_STYLES = map[rune]string{
+	'*': "b", // Bold.
+	'/': "i", // Italic.
+	'_': "u", // Underline.
+	'~': "s", // Strikethrough.
+}
+
+// PangoMarkup turns a message into pango markup.
+// Problems with the markup are reported when the affirmations load.
 func PangoMarkup(text string) (markup string) {
-	markup = text
-
-	// Replace all / with <i> and </i>
-	open := true
-	for strings.Index(markup, "/") != -1 {
-		if open {
-			markup = strings.Replace(markup, "/", "<i>", 1)
-		} else {
-			markup = strings.Replace(markup, "/", "<~i>", 1)
+	markup, _ = parseMarkup(text, 1)
+	return markup
+}
+
+// openMarkup is markup waiting to be closed.
+type openMarkup struct {
+	opener   rune   // The character that opened it.
+	tag      string // The pango tag to close, "" if the markup is being kept as plain text.
+	literal  string // The markup as written, to put back if it never closes.
+	segment  int    // Where the opening tag is in the markup.
+	column   int    // Where the markup is in the file.
+	reported bool   // If true, a problem has already been reported.
+}
+
+// parseMarkup turns a message into pango markup, escaping anything pango would read as markup.
+// Styles only open at the start of a word and close at the end of one, so "1/2" and "http://" stay as they are.
+// Markup that is never closed is kept as plain text.
+// Mistakes in the markup are warnings, as the text is still shown as it was typed.
+func parseMarkup(text string, column int) (markup string, diagnostics []ParseDiagnostic) {
+	var segments []string
+	var open []openMarkup
+	prev, prevOpened := ' ', false // The last character, and whether it opened markup.
+
+	problem := func(offset int, message, token string) {
+		diagnostics = append(diagnostics, ParseDiagnostic{
+			Column:   column + offset,
+			Severity: SEVERITY_WARNING,
+			Message:  message,
+			Token:    token,
+		})
+	}
+
+	// Where some markup is open, -1 if not.
+	find := func(opener rune) (index int) {
+		for index = len(open) - 1; index >= 0; index-- {
+			if open[index].opener == opener {
+				return index
+			}
+		}
+		return -1
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		next, nextSize := utf8.DecodeRuneInString(text[i+size:]) // utf8.RuneError at the end.
+		atEnd := i+size == len(text)
+		opened := false
+
+		// Markup opens before the text of a word and closes after it.
+		canOpen := !atEnd && !unicode.IsSpace(next) && (unicode.IsSpace(prev) || prevOpened || strings.ContainsRune(_MARKUP_OPENS_AFTER, prev))
+		canClose := !unicode.IsSpace(prev) && !prevOpened && (atEnd || !(unicode.IsLetter(next) || unicode.IsDigit(next)))
+
+		switch tag, isStyle := _MARKUP_STYLES[r]; {
+
+		case r == _MARKUP_ESCAPE:
+			if atEnd {
+				problem(i, "nothing to escape", string(r))
+				segments = append(segments, escapeMarkup(string(r)))
+				break
+			}
+			if next == 'n' {
+				segments = append(segments, "\n")
+				r = '\n'
+			} else {
+				segments = append(segments, escapeMarkup(string(next)))
+				r = next
+			}
+			size += nextSize
+
+		case isStyle:
+			index := find(r)
+			switch {
+			case index >= 0 && canClose && index == len(open)-1:
+				segments = append(segments, "</"+tag+">")
+				open = open[:index]
+			case index >= 0 && canClose:
+				problem(i, "markup crosses other markup", string(r))
+				open[index].reported = true
+				segments = append(segments, escapeMarkup(string(r)))
+			case canOpen:
+				open = append(open, openMarkup{opener: r, tag: tag, literal: string(r), segment: len(segments), column: i})
+				segments = append(segments, "<"+tag+">")
+				opened = true
+			default:
+				segments = append(segments, escapeMarkup(string(r)))
+			}
+
+		case r == _MARKUP_SPAN_OPEN:
+			// The settings run up to the first space.
+			settings := text[i+size:]
+			if end := strings.IndexAny(settings, " }"); end >= 0 {
+				settings = settings[:end]
+			}
+			attributes, ok := spanAttributes(settings)
+			if !ok || !strings.HasPrefix(text[i+size+len(settings):], " ") {
+				// Keep it as plain text, so the closing brace is too.
+				problem(i, "invalid span, expected {color:size text}", string(r)+settings)
+				open = append(open, openMarkup{opener: r, literal: string(r), segment: len(segments), column: i, reported: true})
+				segments = append(segments, escapeMarkup(string(r)))
+				break
+			}
+			literal := text[i : i+size+len(settings)+1]
+			open = append(open, openMarkup{opener: r, tag: "span", literal: literal, segment: len(segments), column: i})
+			segments = append(segments, "<span"+attributes+">")
+			size = len(literal)
+			r, opened = ' ', true
+
+		case r == _MARKUP_SPAN_CLOSE:
+			index := find(_MARKUP_SPAN_OPEN)
+			switch {
+			case index < 0:
+				problem(i, "unmatched "+string(r), string(r))
+				segments = append(segments, escapeMarkup(string(r)))
+			case index < len(open)-1:
+				problem(i, "markup crosses other markup", string(r))
+				open[index].reported = true
+				segments = append(segments, escapeMarkup(string(r)))
+			case open[index].tag == "":
+				segments = append(segments, escapeMarkup(string(r)))
+				open = open[:index]
+			default:
+				segments = append(segments, "</span>")
+				open = open[:index]
+			}
+
+		default:
+			segments = append(segments, escapeMarkup(string(r)))
 		}
-		open = !open
+
+		prev, prevOpened = r, opened
+		i += size
 	}
-	markup = strings.ReplaceAll(markup, "~", "/")
-
-	// Replace all * with <b> and </b>
-	open = true
-	for strings.Index(markup, "*") != -1 {
-		if open {
-			markup = strings.Replace(markup, "*", "<b>", 1)
-		} else {
-			markup = strings.Replace(markup, "*", "</b>", 1)
+
+	// Anything never closed is just text.
+	for _, unclosed := range open {
+		if !unclosed.reported {
+			problem(unclosed.column, "unclosed "+string(unclosed.opener), unclosed.literal)
 		}
-		open = !open
+		segments[unclosed.segment] = escapeMarkup(unclosed.literal)
 	}
 
-	return "<span>" + markup + "</span>"
+	return "<span>" + strings.Join(segments, "") + "</span>", diagnostics
+}
+
+// spanAttributes turns the settings of a span, like "gold:40", into pango attributes.
+// Each setting is a color or a font size, at most one of each.
+func spanAttributes(settings string) (attributes string, ok bool) {
+	hasColor, hasSize := false, false
+	for _, setting := range strings.Split(settings, ":") {
+		if size, err := strconv.ParseUint(setting, 10, 32); err == nil {
+			if hasSize || size == 0 {
+				return "", false
+			}
+			attributes += fmt.Sprintf(` size="%d"`, size*1024) // In 1024ths of a point.
+			hasSize = true
+			continue
+		}
+		color, err := ParseColor(setting)
+		if err != nil || hasColor {
+			return "", false
+		}
+		attributes += fmt.Sprintf(` foreground="#%02x%02x%02x"`, colorByte(color.Red), colorByte(color.Green), colorByte(color.Blue))
+		if color.Alpha < 1 {
+			attributes += fmt.Sprintf(` fgalpha="%d%%"`, int(color.Alpha*100+0.5))
+		}
+		hasColor = true
+	}
+	return attributes, true
+}
+
+// colorByte is a 0.0-1.0 part of a color as 0-255.
+func colorByte(part float64) (b int) {
+	return int(part*255 + 0.5)
+}
+
+// escapeMarkup keeps pango from reading text as markup.
+func escapeMarkup(text string) (escaped string) {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
 }
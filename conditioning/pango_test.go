@@ -23,9 +23,57 @@ func (s *PangoSuite) Test_PandoMarkup(c *C) {
 		{`something */here/*`, `<span>something <b><i>here</i></b></span>`},
 		{`something /*here*/`, `<span>something <i><b>here</b></i></span>`},
 		{`something *here* *again*`, `<span>something <b>here</b> <b>again</b></span>`},
+		{`something _here_ ~there~`, `<span>something <u>here</u> <s>there</s></span>`},
+		{`*something here*, again`, `<span><b>something here</b>, again</span>`},
+		{`("*here*")`, `<span>("<b>here</b>")</span>`},
+
+		// Only around words.
+		{`1/2 and/or http://example.com/here`, `<span>1/2 and/or http://example.com/here</span>`},
+		{`snake_case and 2 * 3`, `<span>snake_case and 2 * 3</span>`},
+
+		// Escaped.
+		{`me & you <3`, `<span>me &amp; you &lt;3</span>`},
+		{`\*not bold\*`, `<span>*not bold*</span>`},
+		{`a \\ b`, `<span>a \ b</span>`},
+		{`one\ntwo`, "<span>one\ntwo</span>"},
+		{`\{not a span}`, `<span>{not a span}</span>`},
+
+		// Spans.
+		{`{gold here}`, `<span><span foreground="#ffd700">here</span></span>`},
+		{`{40 here} there`, `<span><span size="40960">here</span> there</span>`},
+		{`{#ff000080:40 *here*}`, `<span><span foreground="#ff0000" fgalpha="50%" size="40960"><b>here</b></span></span>`},
+
+		// Mistakes are kept as text.
+		{`*unclosed`, `<span>*unclosed</span>`},
+		{`{beige here}`, `<span>{beige here}</span>`},
+		{`{gold here`, `<span>{gold here</span>`},
+		{`*a /b* c/`, `<span>*a <i>b* c</i></span>`},
 	}
 	for i, test := range tests {
 		comment := Commentf("Case %v: %v", i, test)
 		c.Check(PangoMarkup(test.text), Equals, test.markup, comment)
 	}
 }
+
+func (s *PangoSuite) Test_ParseMarkup(c *C) {
+	tests := []struct {
+		text        string
+		diagnostics []ParseDiagnostic
+	}{
+		{`*here* and {gold:40 there}\n`, nil},
+		{`some *here`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "unclosed *", Token: "*"}}},
+		{`some {gold here`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "unclosed {", Token: "{gold "}}},
+		{`some {beige here}`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "invalid span, expected {color:size text}", Token: "{beige"}}},
+		{`some {gold:gold here}`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "invalid span, expected {color:size text}", Token: "{gold:gold"}}},
+		{`some {0 here}`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "invalid span, expected {color:size text}", Token: "{0"}}},
+		{`some here}`, []ParseDiagnostic{{Column: 19, Severity: SEVERITY_WARNING, Message: "unmatched }", Token: "}"}}},
+		{`*a /b* c/`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "markup crosses other markup", Token: "*"}}},
+		{`{gold *a} b*`, []ParseDiagnostic{{Column: 18, Severity: SEVERITY_WARNING, Message: "markup crosses other markup", Token: "}"}}},
+		{`some \`, []ParseDiagnostic{{Column: 15, Severity: SEVERITY_WARNING, Message: "nothing to escape", Token: `\`}}},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		_, diagnostics := parseMarkup(test.text, 10)
+		c.Check(diagnostics, DeepEquals, test.diagnostics, comment)
+	}
+}
@@ -0,0 +1,23 @@
+package conditioning
+
+const (
+	// The changes a system tells its presenter about.
+	EVENT_SLIDE_CHANGED      Event = iota // A different slide, or part of a slide, is active.
+	EVENT_SLIDES_LOADED                   // The affirmations were loaded from the file.
+	EVENT_ORDER_CHANGED                   // The slide show order changed.
+	EVENT_SLIDE_SHOW_STARTED              // The slide show started running.
+	EVENT_SLIDE_SHOW_STOPPED              // The slide show stopped running.
+	EVENT_SLIDE_SHOW_PAUSED               // The slide show is holding on the current slide.
+	EVENT_SLIDE_SHOW_RESUMED              // The paused slide show is playing again.
+	EVENT_SPEED_CHANGED                   // The slide show speed changed.
+	EVENT_SLIDE_SHOW_ENDED                // The slide show played all it was asked to and stopped.
+)
+
+// Event is a change in the state of a system.
+type Event int
+
+// Presenter shows a system, told whenever the system's state changes.
+// It is called without the system locked, and may be called from the slide show's goroutine.
+type Presenter interface {
+	StateChanged(event Event)
+}
@@ -0,0 +1,247 @@
+package conditioning
+
+import (
+	"time"
+)
+
+// Sequencer decides the order a slide show moves through the affirmations.
+type Sequencer interface {
+	Reset(affirmations []Affirmation) // Start over with a newly loaded set of affirmations.
+	Next(current int) (index int)     // The affirmation to show after the current one.
+	Prev(current int) (index int)     // The affirmation to show before the current one.
+}
+
+// NewOrderedSequencer creates a sequencer that follows the order of the affirmations file.
+func NewOrderedSequencer() Sequencer {
+	return &orderedSequencer{}
+}
+
+// NewShuffledSequencer creates a sequencer that shuffles the affirmations each time through, heavier affirmations showing more often.
+func NewShuffledSequencer() Sequencer {
+	return &shuffledSequencer{}
+}
+
+// NewShuffledNoRepeatSequencer creates a shuffled sequencer that never starts a new shuffle with the affirmation on screen.
+func NewShuffledNoRepeatSequencer() Sequencer {
+	return &shuffledSequencer{noRepeat: true}
+}
+
+// reloader is a sequencer that can carry its order over to a reloaded set of affirmations.
+type reloader interface {
+	reload(previous, affirmations []Affirmation, current int) // current is the affirmation on screen, after reloading.
+}
+
+// orderedSequencer follows the order of the affirmations file.
+type orderedSequencer struct {
+	count int // How many affirmations there are.
+}
+
+// Reset starts over with a newly loaded set of affirmations.
+func (o *orderedSequencer) Reset(affirmations []Affirmation) {
+	o.count = len(affirmations)
+}
+
+// Next is the affirmation after the current one, wrapping to the top of the file.
+func (o *orderedSequencer) Next(current int) (index int) {
+	if o.count == 0 {
+		return 0
+	}
+	return (current + 1) % o.count
+}
+
+// Prev is the affirmation before the current one, wrapping to the bottom of the file.
+func (o *orderedSequencer) Prev(current int) (index int) {
+	if o.count == 0 {
+		return 0
+	}
+	return (current - 1 + o.count) % o.count
+}
+
+// shuffledSequencer shuffles the affirmations each time through.
+type shuffledSequencer struct {
+	cycle
+	noRepeat bool  // If true, a new shuffle never starts with the affirmation on screen.
+	weights  []int // How many times each affirmation shows in a shuffle.
+}
+
+// Reset starts over with a newly loaded set of affirmations.
+func (s *shuffledSequencer) Reset(affirmations []Affirmation) {
+	s.weights = nil
+	for _, affirmation := range affirmations {
+		s.weights = append(s.weights, affirmation.weight())
+	}
+	s.reset()
+}
+
+// reload carries the shuffle over to reloaded affirmations, so nothing already shown this time through shows again.
+// What hasn't been shown yet, and any new affirmations, are shuffled again.
+func (s *shuffledSequencer) reload(previous, affirmations []Affirmation, current int) {
+	s.weights = nil
+	for _, affirmation := range affirmations {
+		s.weights = append(s.weights, affirmation.weight())
+	}
+	s.carryOver(previous, affirmations, func(shown, remaining []int) (order []int) {
+
+		// Each affirmation has the rest of its weight to go, even if the weight changed.
+		counts := append([]int{}, s.weights...)
+		for _, index := range shown {
+			if counts[index] > 0 {
+				counts[index]--
+			}
+		}
+		return weightedShuffle(counts, current)
+	})
+}
+
+// Next is the next affirmation of the shuffle, shuffling again at the end.
+func (s *shuffledSequencer) Next(current int) (index int) {
+	return s.next(current, func(current int) (order []int) {
+		previous := -1
+		if s.noRepeat {
+			previous = current
+		}
+		return weightedShuffle(s.weights, previous)
+	})
+}
+
+// Prev is the previous affirmation of the shuffle.
+func (s *shuffledSequencer) Prev(current int) (index int) {
+	return s.prev(current)
+}
+
+// spacedSequencer orders the affirmations by spaced repetition, due reviews first.
+type spacedSequencer struct {
+	cycle
+	reviews      map[string]reviewState // The review history, shared with the system.
+	now          func() time.Time       // The current time.
+	affirmations []Affirmation          // The affirmations to review.
+}
+
+// Reset starts over with a newly loaded set of affirmations.
+func (s *spacedSequencer) Reset(affirmations []Affirmation) {
+	s.affirmations = affirmations
+	s.reset()
+}
+
+// reload carries the review order over to reloaded affirmations, so nothing already reviewed this time through shows again.
+func (s *spacedSequencer) reload(previous, affirmations []Affirmation, current int) {
+	s.affirmations = affirmations
+	s.carryOver(previous, affirmations, func(shown, remaining []int) (order []int) {
+		return remaining
+	})
+}
+
+// Next is the next affirmation due for review, working out what is due again at the end.
+func (s *spacedSequencer) Next(current int) (index int) {
+	return s.next(current, func(current int) (order []int) {
+		order = spacedOrder(s.affirmations, s.reviews, s.now())
+		// If we are currently looking at the new first affirmation, move that affirmation to the end.
+		if len(order) > 1 && current == order[0] {
+			order = append(order[1:], order[:1]...)
+		}
+		return order
+	})
+}
+
+// Prev is the previous affirmation reviewed.
+func (s *spacedSequencer) Prev(current int) (index int) {
+	return s.prev(current)
+}
+
+// cycle walks through an order of affirmations, asking for a new order at the end.
+type cycle struct {
+	order    []int // The affirmation indexes in the order to show them.
+	position int   // Where in the order we are.
+}
+
+// reset forgets the order so the next step asks for a new one.
+func (c *cycle) reset() {
+	c.order = nil
+	c.position = 0
+}
+
+// next steps forward through the order, asking for a new order at the end.
+func (c *cycle) next(current int, newOrder func(current int) (order []int)) (index int) {
+	c.position++
+	if c.position >= len(c.order) {
+		c.order = newOrder(current)
+		c.position = 0
+	}
+	if len(c.order) == 0 {
+		return current // Nothing to order.
+	}
+	return c.order[c.position]
+}
+
+// prev steps backward through the order, wrapping to the end.
+func (c *cycle) prev(current int) (index int) {
+	if len(c.order) == 0 {
+		return current // Nothing has been ordered yet.
+	}
+	c.position--
+	if c.position < 0 {
+		c.position = len(c.order) - 1
+	}
+	return c.order[c.position]
+}
+
+// carryOver moves the order over to reloaded affirmations, matching them by identity and dropping the ones that are gone.
+// The affirmations not reached yet, along with any new ones, are put in order again for the rest of the way through,
+// knowing what has already been shown.
+func (c *cycle) carryOver(previous, affirmations []Affirmation, reorder func(shown, remaining []int) (order []int)) {
+
+	// Nothing has been ordered yet.
+	if len(c.order) == 0 {
+		c.reset()
+		return
+	}
+
+	// Where each affirmation is now.
+	previousIdentities := identities(previous)
+	newIdentities := identities(affirmations)
+	indexes := map[string]int{}
+	for i, identity := range newIdentities {
+		indexes[identity] = i
+	}
+
+	// Split the order into what has been shown and what hasn't.
+	var shown, remaining []int
+	wasThere := map[string]bool{}
+	for position, index := range c.order {
+		if index < 0 || index >= len(previous) {
+			continue
+		}
+		identity := previousIdentities[index]
+		wasThere[identity] = true
+		newIndex, found := indexes[identity]
+		if !found {
+			continue // Gone.
+		}
+		if position <= c.position {
+			shown = append(shown, newIndex)
+		} else {
+			remaining = append(remaining, newIndex)
+		}
+	}
+
+	// New affirmations haven't been shown either, once each for the reorder to weigh.
+	for i := range affirmations {
+		if !wasThere[newIdentities[i]] {
+			remaining = append(remaining, i)
+		}
+	}
+
+	c.order = append(shown, reorder(shown, remaining)...)
+	c.position = len(shown) - 1
+}
+
+// saveOrder gets the order and where in it we are.
+func (c *cycle) saveOrder() (order []int, position int) {
+	return append([]int{}, c.order...), c.position
+}
+
+// restoreOrder carries on through a saved order.
+func (c *cycle) restoreOrder(order []int, position int) {
+	c.order = append([]int{}, order...)
+	c.position = position
+}
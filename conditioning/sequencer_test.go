@@ -0,0 +1,198 @@
+package conditioning
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type SequencerSuite struct{}
+
+var _ = Suite(&SequencerSuite{})
+
+// Add the tests.
+
+func (s *SequencerSuite) Test_Ordered(c *C) {
+	sequencer := NewOrderedSequencer()
+
+	// Nothing loaded.
+	c.Check(sequencer.Next(0), Equals, 0)
+	c.Check(sequencer.Prev(0), Equals, 0)
+
+	// Wraps around both ends of the file.
+	sequencer.Reset(make([]Affirmation, 3))
+	c.Check(sequencer.Next(-1), Equals, 0)
+	c.Check(sequencer.Next(0), Equals, 1)
+	c.Check(sequencer.Next(2), Equals, 0)
+	c.Check(sequencer.Prev(1), Equals, 0)
+	c.Check(sequencer.Prev(0), Equals, 2)
+}
+
+func (s *SequencerSuite) Test_Shuffled(c *C) {
+	affirmations := []Affirmation{{Weight: 2}, {}, {}}
+
+	for _, sequencer := range []Sequencer{NewShuffledSequencer(), NewShuffledNoRepeatSequencer()} {
+		sequencer.Reset(affirmations)
+
+		// A whole shuffle shows each affirmation by its weight.
+		var order []int
+		counts := make([]int, 3)
+		current := 0
+		for i := 0; i < 4; i++ {
+			current = sequencer.Next(current)
+			order = append(order, current)
+			counts[current]++
+		}
+		c.Check(counts, DeepEquals, []int{2, 1, 1})
+
+		// Going back retraces the shuffle.
+		c.Check(sequencer.Prev(current), Equals, order[2])
+		c.Check(sequencer.Prev(current), Equals, order[1])
+		c.Check(sequencer.Next(current), Equals, order[2])
+		c.Check(sequencer.Next(current), Equals, order[3])
+	}
+
+	// A new shuffle never starts with the affirmation on screen.
+	sequencer := NewShuffledNoRepeatSequencer()
+	sequencer.Reset([]Affirmation{{}, {}})
+	for i := 0; i < 100; i++ {
+		sequencer.Reset([]Affirmation{{}, {}})
+		c.Assert(sequencer.Next(1), Equals, 0)
+	}
+
+	// Nothing loaded.
+	sequencer.Reset(nil)
+	c.Check(sequencer.Next(0), Equals, 0)
+	c.Check(sequencer.Prev(0), Equals, 0)
+}
+
+func (s *SequencerSuite) Test_Spaced(c *C) {
+	now := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	sequencer := &spacedSequencer{
+		reviews: map[string]reviewState{
+			"a": {Due: now.Add(-time.Hour)},
+			"b": {Due: now.Add(_DAY)},
+			"c": {Due: now.Add(-2 * time.Hour)},
+		},
+		now: func() time.Time { return now },
+	}
+	sequencer.Reset([]Affirmation{{Message: "a"}, {Message: "b"}, {Message: "c"}})
+
+	// Only the due affirmations, most overdue first.
+	c.Check(sequencer.Next(1), Equals, 2)
+	c.Check(sequencer.Next(2), Equals, 0)
+	c.Check(sequencer.Next(0), Equals, 2)
+
+	// The new review doesn't start with the affirmation on screen.
+	sequencer.Reset([]Affirmation{{Message: "a"}, {Message: "b"}, {Message: "c"}})
+	c.Check(sequencer.Next(2), Equals, 0)
+}
+
+func (s *SequencerSuite) Test_Reload(c *C) {
+	affirmations := []Affirmation{{Message: "a"}, {Message: "b"}, {Message: "c"}, {Message: "d"}, {Message: "e"}}
+
+	for attempt := 0; attempt < 20; attempt++ {
+		sequencer := NewShuffledNoRepeatSequencer()
+		sequencer.Reset(affirmations)
+
+		// Part way through a shuffle.
+		current := -1
+		shown := map[string]bool{}
+		for i := 0; i < 3; i++ {
+			current = sequencer.Next(current)
+			shown[affirmations[current].Message] = true
+		}
+		onScreen := affirmations[current].Message
+
+		// Reload with a line added at the top and one removed.
+		var reloaded []Affirmation
+		reloaded = append(reloaded, Affirmation{Message: "new"})
+		for _, affirmation := range affirmations {
+			if affirmation.Message != "e" {
+				reloaded = append(reloaded, affirmation)
+			}
+		}
+		current, _ = findIdentity(reloaded, Affirmation{Message: onScreen}.identity())
+		sequencer.(reloader).reload(affirmations, reloaded, current)
+
+		// The rest of the shuffle is what wasn't shown, and the new affirmation.
+		expected := map[string]bool{}
+		for _, affirmation := range reloaded {
+			if !shown[affirmation.Message] {
+				expected[affirmation.Message] = true
+			}
+		}
+		rest := map[string]bool{}
+		for len(rest) < len(expected) {
+			current = sequencer.Next(current)
+			message := reloaded[current].Message
+			c.Assert(expected[message], Equals, true, Commentf("repeated %s", message))
+			c.Assert(rest[message], Equals, false, Commentf("repeated %s", message))
+			rest[message] = true
+		}
+		c.Check(rest["new"], Equals, true)
+
+		// Going back goes back through what was shown.
+		c.Check(reloaded[sequencer.Prev(current)].Message, Not(Equals), "e")
+	}
+
+	// Nothing ordered yet starts fresh.
+	sequencer := NewShuffledSequencer()
+	sequencer.Reset(affirmations)
+	sequencer.(reloader).reload(affirmations, affirmations[:2], 0)
+	c.Check(sequencer.Next(0) < 2, Equals, true)
+}
+
+func (s *SequencerSuite) Test_CarryOver(c *C) {
+	affirmations := []Affirmation{{Message: "a"}, {Message: "a"}, {Message: "b"}}
+
+	// Each copy of a repeated message carries over on its own.
+	cycle := &cycle{}
+	cycle.restoreOrder([]int{0, 1, 2}, 0)
+	cycle.carryOver(affirmations, affirmations, func(shown, remaining []int) (order []int) {
+		return remaining
+	})
+	order, position := cycle.saveOrder()
+	c.Check(order, DeepEquals, []int{0, 1, 2})
+	c.Check(position, Equals, 0)
+}
+
+func (s *SequencerSuite) Test_ReloadWeights(c *C) {
+	affirmations := []Affirmation{{Message: "a"}, {Message: "b"}}
+	sequencer := &shuffledSequencer{}
+	sequencer.Reset(affirmations)
+	current := sequencer.Next(-1)
+
+	// A heavier affirmation has the rest of its new weight to go.
+	heavier := []Affirmation{{Message: "a", Weight: 3}, {Message: "b", Weight: 3}}
+	sequencer.reload(affirmations, heavier, current)
+	order, position := sequencer.saveOrder()
+	c.Check(position, Equals, 0)
+	counts := map[int]int{}
+	for _, index := range order[1:] {
+		counts[index]++
+	}
+	c.Check(counts, DeepEquals, map[int]int{current: 2, 1 - current: 3})
+
+	// A lighter affirmation already shown enough doesn't show again this time through.
+	sequencer.reload(heavier, affirmations, current)
+	order, position = sequencer.saveOrder()
+	c.Check(position, Equals, 0)
+	c.Check(order[1:], DeepEquals, []int{1 - current})
+}
+
+func (s *SequencerSuite) Test_ReloadSpaced(c *C) {
+	now := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	affirmations := []Affirmation{{Message: "a"}, {Message: "b"}}
+	sequencer := &spacedSequencer{reviews: map[string]reviewState{}, now: func() time.Time { return now }}
+	sequencer.Reset(affirmations)
+	current := sequencer.Next(-1)
+
+	// A new weighted affirmation is reviewed once, like any other.
+	reloaded := append(affirmations, Affirmation{Message: "c", Weight: 3})
+	sequencer.reload(affirmations, reloaded, current)
+	order, position := sequencer.saveOrder()
+	c.Check(position, Equals, 0)
+	c.Check(order, DeepEquals, []int{0, 1, 2})
+}
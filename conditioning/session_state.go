@@ -0,0 +1,172 @@
+package conditioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// The session state files are kept for each user, one for each affirmations file.
+	_STATE_DIR         = "conditioning/state" // Within the user's config folder.
+	_STATE_FILE_SUFFIX = ".json"
+)
+
+// sessionState is where a session left off, so the next launch can carry on from there.
+type sessionState struct {
+	ActiveIndex int    // The affirmation on screen.
+	Identity    string // The identity of the affirmation on screen, to find it if the file changed.
+	DisplayBoth bool   // If false, only the image was showing.
+	Random      bool   // If true the slide show was random.
+	Spaced      bool   // If true the slide show was spaced repetition.
+	Order       []int  // The order the slide show was working through, if it had one.
+	Position    int    // Where in the order the slide show was.
+}
+
+// orderKeeper is a sequencer whose order can be saved and restored.
+type orderKeeper interface {
+	saveOrder() (order []int, position int)
+	restoreOrder(order []int, position int)
+}
+
+// SaveState saves where this session is, for the next launch to carry on from.
+func (s *System) SaveState() (err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	// Nothing to come back to.
+	if len(s.affirmations) == 0 {
+		return nil
+	}
+
+	state := sessionState{
+		ActiveIndex: s.activeAffirmationIndex,
+		Identity:    identities(s.loadedAffirmations())[s.activeAffirmationIndex],
+		DisplayBoth: s.displayBoth,
+		Random:      s.slideShowRandom,
+		Spaced:      s.slideShowSpaced,
+	}
+	if keeper, ok := s.sequencer.(orderKeeper); ok {
+		state.Order, state.Position = keeper.saveOrder()
+	}
+
+	filename, err := stateFilename(s.affirmationFilename)
+	if err != nil {
+		return err
+	}
+	return saveSessionState(filename, state)
+}
+
+// RestoreState carries on from where the last session left off, if the affirmations file still matches.
+// The affirmations must be loaded first.
+func (s *System) RestoreState() (err error) {
+	s.mux.Lock()
+	defer s.present(EVENT_SLIDE_CHANGED) // After unlocking.
+	defer s.mux.Unlock()
+
+	filename, err := stateFilename(s.affirmationFilename)
+	if err != nil {
+		return err
+	}
+	state, found, err := loadSessionState(filename)
+	if err != nil {
+		return err
+	}
+
+	// Is the affirmation still there?
+	if !found {
+		return nil // Start fresh.
+	}
+	activeIndex, found := findIdentity(s.loadedAffirmations(), state.Identity)
+	if !found {
+		return nil // Start fresh.
+	}
+
+	// The review history is needed before we can order anything.
+	if state.Spaced && s.reviews == nil {
+		if s.reviews, err = loadReviews(reviewFilename(s.affirmationFilename)); err != nil {
+			return err
+		}
+		s.spacedSequencer.reviews = s.reviews
+	}
+
+	s.activeAffirmationIndex = activeIndex
+	s.displayBoth = state.DisplayBoth
+	s.slideShowRandom = state.Random
+	s.slideShowSpaced = state.Spaced
+	s.chooseSequencer()
+
+	// Carry on through the same order, if the file is as it was.
+	if keeper, ok := s.sequencer.(orderKeeper); ok && activeIndex == state.ActiveIndex && validOrder(state.Order, state.Position, len(s.affirmations)) {
+		keeper.restoreOrder(state.Order, state.Position)
+	}
+
+	return nil
+}
+
+// validOrder checks a saved order only has affirmations there are, and the position is in it.
+func validOrder(order []int, position int, count int) (valid bool) {
+	if position < 0 || position >= len(order) {
+		return false
+	}
+	for _, index := range order {
+		if index < 0 || index >= count {
+			return false
+		}
+	}
+	return true
+}
+
+// stateFilename is the session state file for an affirmations file, in the user's config folder.
+// It is named for the affirmations file and a hash of where it is, so each affirmations file has its own.
+func stateFilename(affirmationFilename string) (filename string, err error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", Error(err)
+	}
+	absoluteFilename, err := filepath.Abs(affirmationFilename)
+	if err != nil {
+		return "", Error(err)
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(absoluteFilename))
+	name := fmt.Sprintf("%s-%016x%s", filepath.Base(absoluteFilename), hash.Sum64(), _STATE_FILE_SUFFIX)
+	return filepath.Join(configDir, filepath.FromSlash(_STATE_DIR), name), nil
+}
+
+// loadSessionState loads where the last session left off, if there was one.
+func loadSessionState(filename string) (state sessionState, found bool, err error) {
+
+	// No file means no last session.
+	bytes, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return sessionState{}, false, nil
+	}
+	if err != nil {
+		return sessionState{}, false, Error(err)
+	}
+
+	if err = json.Unmarshal(bytes, &state); err != nil {
+		return sessionState{}, false, Error(err)
+	}
+
+	return state, true, nil
+}
+
+// saveSessionState saves where a session is, making the folder for it if needed.
+func saveSessionState(filename string, state sessionState) (err error) {
+	bytes, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return Error(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return Error(err)
+	}
+	if err = ioutil.WriteFile(filename, bytes, 0644); err != nil {
+		return Error(err)
+	}
+	return nil
+}
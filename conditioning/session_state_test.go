@@ -0,0 +1,133 @@
+package conditioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type SessionStateSuite struct{}
+
+var _ = Suite(&SessionStateSuite{})
+
+// Add the tests.
+
+func (s *SessionStateSuite) Test_SaveRestoreState(c *C) {
+	dir, err := ioutil.TempDir("", "conditioning")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	affirmationFilename := filepath.Join(dir, "slides", "affirmations.txt")
+
+	// The state is kept in the user's config folder, not with the affirmations.
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	c.Assert(os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config")), IsNil)
+	filename, err := stateFilename(affirmationFilename)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Dir(filename), Equals, filepath.Join(dir, "config", "conditioning", "state"))
+	c.Check(filepath.Base(filename), Matches, `affirmations\.txt-[0-9a-f]{16}\.json`)
+
+	// Each affirmations file has its own, wherever it is given from.
+	other, err := stateFilename(filepath.Join(dir, "other", "affirmations.txt"))
+	c.Assert(err, IsNil)
+	c.Check(other, Not(Equals), filename)
+	working, err := os.Getwd()
+	c.Assert(err, IsNil)
+	relative, err := filepath.Rel(working, affirmationFilename)
+	c.Assert(err, IsNil)
+	same, err := stateFilename(relative)
+	c.Assert(err, IsNil)
+	c.Check(same, Equals, filename)
+
+	// A system with some affirmations.
+	newSystem := func(messages ...string) (system *System) {
+		system, err := NewSystem(testSystemConfig, affirmationFilename, "images/")
+		c.Assert(err, IsNil)
+		for _, message := range messages {
+			system.affirmations = append(system.affirmations, Slide{Affirmation: Affirmation{Message: message}})
+		}
+		system.chooseSequencer()
+		return system
+	}
+	active := func(system *System) (index int) {
+		index, _, _, _, _ = system.DisplayTextImage()
+		return index
+	}
+	messages := []string{"I am calm.", "I am strong.", "I am kind.", "I am brave.", "I am here."}
+
+	// Nothing saved yet starts fresh.
+	fresh := newSystem(messages...)
+	c.Assert(fresh.RestoreState(), IsNil)
+	c.Check(active(fresh), Equals, 0)
+
+	// Part way through a random slide show.
+	original := newSystem(messages...)
+	original.RandomOnOff()
+	for i := 0; i < 3; i++ {
+		c.Assert(original.Right(), IsNil)
+	}
+	c.Assert(original.SaveState(), IsNil)
+	_, err = os.Stat(filename)
+	c.Check(err, IsNil)
+
+	// The next launch carries on through the same shuffle.
+	restored := newSystem(messages...)
+	c.Assert(restored.RestoreState(), IsNil)
+	c.Check(active(restored), Equals, active(original))
+	c.Check(restored.slideShowRandom, Equals, true)
+	c.Check(restored.sequencer, Equals, restored.randomSequencer)
+	for i := 0; i < 2; i++ {
+		c.Assert(original.Right(), IsNil)
+		c.Assert(restored.Right(), IsNil)
+		c.Check(active(restored), Equals, active(original))
+	}
+	for i := 0; i < 4; i++ {
+		c.Assert(original.Left(), IsNil)
+		c.Assert(restored.Left(), IsNil)
+		c.Check(active(restored), Equals, active(original))
+	}
+
+	// An ordered slide show keeps its place.
+	ordered := newSystem(messages...)
+	for i := 0; i < 3; i++ {
+		c.Assert(ordered.Right(), IsNil)
+	}
+	c.Assert(ordered.SaveState(), IsNil)
+	restored = newSystem(messages...)
+	c.Assert(restored.RestoreState(), IsNil)
+	c.Check(active(restored), Equals, 2)
+	c.Check(restored.displayBoth, Equals, true)
+	c.Check(restored.slideShowRandom, Equals, false)
+
+	// A changed affirmations file finds the affirmation where it went.
+	changed := newSystem("I am new.", "I am calm.", "I am strong.", "I am kind.")
+	c.Assert(changed.RestoreState(), IsNil)
+	c.Check(active(changed), Equals, 3)
+
+	// Starts fresh if the affirmation is gone.
+	shorter := newSystem("I am calm.", "I am strong.")
+	c.Assert(shorter.RestoreState(), IsNil)
+	c.Check(active(shorter), Equals, 0)
+}
+
+func (s *SessionStateSuite) Test_ValidOrder(c *C) {
+	tests := []struct {
+		order    []int
+		position int
+		count    int
+		valid    bool
+	}{
+		{[]int{2, 0, 1}, 0, 3, true},
+		{[]int{2, 0, 1}, 2, 3, true},
+		{[]int{2, 0, 1}, 3, 3, false},
+		{[]int{2, 0, 1}, -1, 3, false},
+		{[]int{2, 0, 1}, 0, 2, false},
+		{[]int{-1}, 0, 2, false},
+		{nil, 0, 2, false},
+	}
+	for i, test := range tests {
+		c.Check(validOrder(test.order, test.position, test.count), Equals, test.valid, Commentf("Case %v: %v", i, test))
+	}
+}
@@ -0,0 +1,148 @@
+package conditioning
+
+const (
+	// The outline.
+	OUTLINE    = true
+	NO_OUTLINE = false
+)
+
+// Slide is an affirmation prepared for display.
+type Slide struct {
+	Affirmation  Affirmation   // The affirmation loaded from a file.
+	DisplayText  DisplayText   // The text prepared for rendering.
+	DisplayImage *DisplayImage // The image prepared for rendering, if there is one.
+}
+
+// SlidePreparer measures text and loads images for display on the screen.
+type SlidePreparer interface {
+	// PrepareText lays out a message for display on the screen.
+	PrepareText(config Config, message string, textProperties TextProperties) (displayText DisplayText)
+	// PrepareImage loads an image and places it on the screen.
+	PrepareImage(config Config, imagePath string, affirmationImage AffirmationImage) (displayImage DisplayImage, err error)
+}
+
+// DisplayText is everything neded to display text on the screen.
+type DisplayText struct {
+	// Coordinate.
+	X int // Coordinate on the screen.
+	Y int // Coordiante on the screen.
+	// Size.
+	Width  int // Width of the laid out text on the screen.
+	Height int // Height of the laid out text on the screen.
+	// Text.
+	PangoMarkup string     // The text to display with optional formatting.
+	Runs        []TextRun  // The text laid out into lines, placed from the coordinate.
+	Layout      TextLayout // How the text wraps and fits.
+	FontFace    string     // The font.
+	FontSize    uint       // The font size to use, after any shrinking to fit.
+	// The color and outline.
+	Color        Color   // The color of the text.
+	Opacity      float64 // How solid the text and outline are, 0.0-1.0.
+	Outline      bool    // If true, put an outline underneath the text.
+	OutlineColor Color   // The color of the outline.
+	OutlineScale float64 // The 0.0-1.0 % of the font size for the outline (only half will show).
+	// The effects behind the text.
+	Shadow Shadow // A soft copy of the text off to the side.
+	Glow   Glow   // A soft halo around the text.
+	Panel  Panel  // A box behind all the text.
+}
+
+// DisplayImage is everything neded to display image on the screen.
+type DisplayImage struct {
+	// Coordinate.
+	X float64 // Coordinate on the screen.
+	Y float64 // Coordinate on the screen.
+	// Size.
+	Width  int // Width of the image on the screen.
+	Height int // Height of the image on the screen.
+	// Image.
+	Filename string
+	Image    Image // The image data itself.
+}
+
+// Image is loaded image data.
+type Image interface {
+	// Pixels gets the pixel data of the image.
+	Pixels() (pixels ImagePixels)
+}
+
+// ImagePixels is the pixel data of an image.
+type ImagePixels struct {
+	Width     int    // Width in pixels.
+	Height    int    // Height in pixels.
+	Rowstride int    // Bytes from the start of one row to the next.
+	Channels  int    // Bytes in each pixel: red, green, blue, and maybe alpha.
+	Pixels    []byte // The pixel data.
+}
+
+// StyleText prepares everything about a message's display that doesn't need it measured: the markup, font, colors and effects.
+func StyleText(config Config, message string, textProperties TextProperties) (displayText DisplayText) {
+
+	// Create markup.
+	displayText.PangoMarkup = PangoMarkup(message)
+
+	// How the text wraps and fits.
+	displayText.Layout = TextLayoutFor(config, textProperties.Layout)
+
+	// Font information.
+	displayText.FontFace = config.FontFace
+	displayText.FontSize = config.FontSize
+	if textProperties.FontSize != 0 {
+		displayText.FontSize = textProperties.FontSize
+	}
+
+	// Color and outline.
+	displayText.Color, displayText.OutlineColor, displayText.OutlineScale, displayText.Opacity = textColors(config, textProperties)
+	displayText.Outline = OUTLINE // Always outline.
+	displayText.Shadow, displayText.Glow, displayText.Panel = textEffects(config, textProperties)
+
+	return displayText
+}
+
+// LoadSlides loads and prepares affirmations for display, outside of a running system.
+func LoadSlides(preparer SlidePreparer, config Config, affirmationFilename, imagePath string) (slides []Slide, err error) {
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Load from the text file.
+	affirmations, _, diagnostics, err := LoadAffirmations(affirmationFilename)
+	if err != nil {
+		return nil, Error(err)
+	}
+	if err = reportDiagnostics(config, affirmationFilename, diagnostics); err != nil {
+		return nil, err
+	}
+
+	// Prepare the affirmation data.
+	return PrepareSlides(preparer, config, imagePath, affirmations)
+}
+
+// PrepareSlides prepares affirmations for display.
+func PrepareSlides(preparer SlidePreparer, config Config, imagePath string, affirmations []Affirmation) (slides []Slide, err error) {
+	for _, affirmation := range affirmations {
+
+		// Prep the parts that must exist.
+		slide := Slide{
+			Affirmation: affirmation,
+			DisplayText: preparer.PrepareText(config, affirmation.Message, affirmation.Text),
+		}
+
+		// Is there an image?
+		if affirmation.Image.Filename != "" {
+			displayImage, err := preparer.PrepareImage(config, imagePath, affirmation.Image)
+			if err != nil {
+				return nil, Error(err)
+			}
+			slide.DisplayImage = &displayImage
+		}
+
+		// Automatic colors stand out from the image.
+		slide.DisplayText = matchBackground(config, affirmation.Text, slide.DisplayText, slide.DisplayImage)
+
+		// Add the affirmation to the prepared affirmations.
+		slides = append(slides, slide)
+	}
+
+	return slides, nil
+}
@@ -0,0 +1,383 @@
+package conditioning
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// The slide show speed, as a percent of the configured timing.
+	_SPEED_NORMAL = 100
+)
+
+// The speeds the slide show steps through, slowest first.
+var _SPEED_STEPS = []int{25, 50, 75, _SPEED_NORMAL, 150, 200, 300, 400}
+
+// StartStopSlideShow starts a slide show or stops a running one.
+func (s *System) StartStopSlideShow() (err error) {
+	event := EVENT_SLIDE_SHOW_STARTED
+	s.mux.Lock()
+	defer func() { s.present(event) }() // After unlocking.
+	defer s.mux.Unlock()
+
+	// If the slide show isn't running, we need to start the slide show.
+	if !s.slideShowRunning {
+		s.startSlideShow()
+	} else {
+		// The slide show is running, we need to end the slide show.
+		event = EVENT_SLIDE_SHOW_STOPPED
+		s.stopSlideShow()
+	}
+
+	return nil
+}
+
+// Pause holds a playing slide show on the current slide, keeping the time left on it.
+func (s *System) Pause() (err error) {
+	paused := false
+	s.mux.Lock()
+	defer func() {
+		if paused {
+			s.present(EVENT_SLIDE_SHOW_PAUSED) // After unlocking.
+		}
+	}()
+	defer s.mux.Unlock()
+
+	// Only a playing slide show can pause.
+	if !s.slideShowRunning || s.slideShowPaused {
+		return nil
+	}
+
+	// Remember how long the slide had left.
+	s.slideShowPausedAt = s.clock.Now()
+	s.slideShowRemaining = s.slideShowDeadline.Sub(s.slideShowPausedAt)
+	if s.slideShowRemaining < 0 {
+		s.slideShowRemaining = 0
+	}
+	s.stopTimer()
+	s.slideShowPaused = true
+	paused = true
+
+	return nil
+}
+
+// Resume continues a paused slide show with the time left on its slide, or starts a stopped one.
+func (s *System) Resume() (err error) {
+	event, changed := EVENT_SLIDE_SHOW_RESUMED, false
+	s.mux.Lock()
+	defer func() {
+		if changed {
+			s.present(event) // After unlocking.
+		}
+	}()
+	defer s.mux.Unlock()
+
+	switch {
+
+	// Nothing to resume, start from the beginning.
+	case !s.slideShowRunning:
+		event, changed = EVENT_SLIDE_SHOW_STARTED, true
+		s.startSlideShow()
+
+	// Pick up where we paused, the pause not counting against the session.
+	case s.slideShowPaused:
+		changed = true
+		s.slideShowPaused = false
+		s.slideShowEnds = s.slideShowEnds.Add(s.clock.Now().Sub(s.slideShowPausedAt))
+		s.startTimer(s.slideShowRemaining)
+	}
+
+	return nil
+}
+
+// IsPlaying is true if the slide show is started and not paused.
+func (s *System) IsPlaying() (playing bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.slideShowRunning && !s.slideShowPaused
+}
+
+// IsPaused is true if the slide show is started and paused.
+func (s *System) IsPaused() (paused bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.slideShowRunning && s.slideShowPaused
+}
+
+// Finished is true if the slide show played to its end, until it is started again or navigated.
+func (s *System) Finished() (finished bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.slideShowFinished
+}
+
+// SpeedUp shortens the time each slide shows by one step.
+func (s *System) SpeedUp() (err error) {
+	return s.changeSpeed(1)
+}
+
+// SlowDown lengthens the time each slide shows by one step.
+func (s *System) SlowDown() (err error) {
+	return s.changeSpeed(-1)
+}
+
+// Speed gets the speed as a percent of the configured timing, and how long the active slide shows at that speed.
+func (s *System) Speed() (percent int, interval time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.slideShowSpeed, s.slideDuration()
+}
+
+// SaveSpeed writes the configured timing at the current speed to a config file.
+// Only the timing in the file changes, so settings given some other way (like on the command line) are not saved,
+// and settings left out of the file stay out.
+// Display times given on affirmations are in the affirmations file and are not changed.
+func (s *System) SaveSpeed(configFilename string) (err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	// The timing in use, leaving out what isn't set.
+	sped := speedConfig(s.config, s.slideShowSpeed)
+	timing := map[string]interface{}{"SleepMilli": sped.SleepMilli}
+	if sped.WordsPerMinute != 0 {
+		timing["WordsPerMinute"] = sped.WordsPerMinute
+	}
+	if sped.MinSleepMilli != 0 {
+		timing["MinSleepMilli"] = sped.MinSleepMilli
+	}
+	if sped.MaxSleepMilli != 0 {
+		timing["MaxSleepMilli"] = sped.MaxSleepMilli
+	}
+
+	return updateConfigFile(configFilename, timing)
+}
+
+// changeSpeed moves the speed a number of steps, keeping the same share of the active slide's time left.
+func (s *System) changeSpeed(steps int) (err error) {
+	s.mux.Lock()
+	defer s.present(EVENT_SPEED_CHANGED) // After unlocking.
+	defer s.mux.Unlock()
+
+	// Is there a step in that direction?
+	step := sort.SearchInts(_SPEED_STEPS, s.slideShowSpeed) + steps
+	if step < 0 || step >= len(_SPEED_STEPS) {
+		return nil // Already as fast or slow as it goes.
+	}
+	oldSpeed := s.slideShowSpeed
+	s.slideShowSpeed = _SPEED_STEPS[step]
+
+	// Rescale the time left on the active slide.
+	rescale := func(duration time.Duration) (rescaled time.Duration) {
+		if duration < 0 {
+			return 0
+		}
+		return duration * time.Duration(oldSpeed) / time.Duration(s.slideShowSpeed)
+	}
+	switch {
+	case s.slideShowPaused:
+		s.slideShowRemaining = rescale(s.slideShowRemaining)
+	case s.slideShowTimer != nil:
+		remaining := rescale(s.slideShowDeadline.Sub(s.clock.Now()))
+		s.stopTimer()
+		s.startTimer(remaining)
+	}
+
+	return nil
+}
+
+// speedConfig is the configured timing sped up to a percent of itself.
+func speedConfig(config Config, percent int) (sped Config) {
+	sped = config
+
+	// Slides show for less time, and are read faster.
+	scale := func(milli uint) (scaled uint) {
+		scaled = milli * _SPEED_NORMAL / uint(percent)
+		if milli != 0 && scaled == 0 {
+			scaled = 1 // Never turn a time into no time.
+		}
+		return scaled
+	}
+	sped.SleepMilli = scale(config.SleepMilli)
+	sped.MinSleepMilli = scale(config.MinSleepMilli)
+	sped.MaxSleepMilli = scale(config.MaxSleepMilli)
+	sped.WordsPerMinute = config.WordsPerMinute * uint(percent) / _SPEED_NORMAL
+	if config.WordsPerMinute != 0 && sped.WordsPerMinute == 0 {
+		sped.WordsPerMinute = 1 // Never stop reading.
+	}
+
+	return sped
+}
+
+// startSlideShow starts playing the slide show, giving the current slide its whole time.
+func (s *System) startSlideShow() {
+	s.slideShowRunning = true
+	s.slideShowPaused = false
+	s.slideShowFinished = false
+
+	// A new session.
+	s.slideShowShown = 1
+	s.slideShowEnds = s.clock.Now().Add(time.Duration(s.config.StopAfterMilli) * time.Millisecond)
+
+	s.startTimer(s.slideDuration())
+}
+
+// stopSlideShow stops the slide show, leaving the whole slide showing.
+func (s *System) stopSlideShow() {
+	s.stopTimer()
+	s.slideShowRunning = false
+	s.slideShowPaused = false
+	s.displayBoth = true // The slide show was showing the whole slide.
+}
+
+// skipTo moves a started slide show to an affirmation, giving it its whole time whether playing or paused.
+func (s *System) skipTo(index int) {
+	if index == s.activeAffirmationIndex || index < 0 || index > s.maxAffirmationIndex() {
+		return
+	}
+	s.moveTo(index)
+	if s.slideShowTimer != nil {
+		s.stopTimer()
+		s.startTimer(s.slideDuration())
+	}
+}
+
+// slideShowOver is true if the session has shown all its cycles or run out of time.
+func (s *System) slideShowOver() (over bool) {
+	if s.config.StopAfterCycles > 0 && s.slideShowShown >= int(s.config.StopAfterCycles)*s.cycleLength() {
+		return true
+	}
+	if s.config.StopAfterMilli > 0 && !s.clock.Now().Before(s.slideShowEnds) {
+		return true
+	}
+	return false
+}
+
+// cycleLength is how many slides show in one full pass through the affirmations.
+func (s *System) cycleLength() (length int) {
+
+	// A random pass shows heavier affirmations more than once.
+	if s.sequencer == s.randomSequencer {
+		for _, data := range s.affirmations {
+			length += data.Affirmation.weight()
+		}
+		return length
+	}
+
+	return len(s.affirmations)
+}
+
+// startTimer starts the timer that moves the slide show on after the current slide's time, or when the session ends.
+func (s *System) startTimer(duration time.Duration) {
+	duration = s.sessionDuration(duration)
+	timer := s.clock.NewTimer(duration)
+	doneChan := make(chan bool)
+	s.slideShowTimer = timer
+	s.slideShowDoneChan = doneChan
+	s.slideShowDeadline = s.clock.Now().Add(duration)
+
+	// Start go routine that operates the slide show.
+	go s.runSlideShow(timer, doneChan)
+}
+
+// sessionDuration shortens a slide's time so the slide show ends on time.
+func (s *System) sessionDuration(duration time.Duration) (shortened time.Duration) {
+	if s.config.StopAfterMilli == 0 {
+		return duration
+	}
+	if left := s.slideShowEnds.Sub(s.clock.Now()); left < duration {
+		duration = left
+	}
+	if duration < 0 {
+		duration = 0
+	}
+	return duration
+}
+
+// stopTimer stops the slide show timer, if there is one.
+func (s *System) stopTimer() {
+	if s.slideShowTimer == nil {
+		return
+	}
+
+	s.slideShowTimer.Stop()    // Stop timer.
+	close(s.slideShowDoneChan) // Escape the golang function responding to the timer.
+
+	s.slideShowTimer = nil
+	s.slideShowDoneChan = nil
+}
+
+// runSlideShow moves the slide show on each time the timer fires, until told it is done.
+func (s *System) runSlideShow(timer Timer, doneChan chan bool) {
+	for { // Infinite loop.
+		select {
+
+		// The slide's time is up.
+		case <-timer.C():
+			s.mux.Lock()
+			if s.slideShowTimer != timer {
+				s.mux.Unlock()
+				return // The slide show was stopped meanwhile.
+			}
+
+			// Is the session over?
+			if s.slideShowOver() {
+				s.stopSlideShow()
+				s.slideShowFinished = true
+				s.mux.Unlock()
+				s.present(EVENT_SLIDE_SHOW_ENDED)
+				return
+			}
+
+			// Pick a new slide, scheduled for its own duration.
+			s.nextSlide()
+			s.slideShowShown++
+			duration := s.sessionDuration(s.slideDuration())
+			timer.Reset(duration)
+			s.slideShowDeadline = s.clock.Now().Add(duration)
+			s.mux.Unlock()
+			s.present(EVENT_SLIDE_CHANGED)
+
+		// A stop command.
+		case <-doneChan:
+			return // kill the goroutine.
+		}
+	}
+}
+
+// slideDuration is how long the active slide stays on screen in a slide show, at the current speed.
+func (s *System) slideDuration() (duration time.Duration) {
+	var affirmation Affirmation
+	if len(s.affirmations) > 0 {
+		affirmation = s.affirmations[s.activeAffirmationIndex].Affirmation
+	}
+	return affirmationDuration(s.config, affirmation) * _SPEED_NORMAL / time.Duration(s.slideShowSpeed)
+}
+
+// affirmationDuration is how long an affirmation stays on screen in a slide show.
+func affirmationDuration(config Config, affirmation Affirmation) (duration time.Duration) {
+
+	// An explicit time always wins.
+	if affirmation.Duration > 0 {
+		return affirmation.Duration
+	}
+
+	// Are we timing slides by how long they take to read?
+	if config.WordsPerMinute > 0 {
+		words := len(strings.Fields(affirmation.Message))
+		milli := uint(words) * 60000 / config.WordsPerMinute
+		if milli < config.MinSleepMilli {
+			milli = config.MinSleepMilli
+		}
+		if config.MaxSleepMilli != 0 && milli > config.MaxSleepMilli {
+			milli = config.MaxSleepMilli
+		}
+		return time.Duration(milli) * time.Millisecond
+	}
+
+	return time.Duration(config.SleepMilli) * time.Millisecond
+}
@@ -0,0 +1,151 @@
+package conditioning
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	// The spaced repetition review file sits next to the affirmations file.
+	_REVIEW_FILE_SUFFIX = ".review.json"
+
+	// SM-2 settings.
+	_SM2_INITIAL_EASE = 2.5 // The ease of a new affirmation.
+	_SM2_MINIMUM_EASE = 1.3 // Ease never drops below this.
+	_SM2_KNOWN        = 4   // The 0-5 quality of a "known" grade.
+	_SM2_NOT_YET      = 1   // The 0-5 quality of a "not yet" grade.
+	_SM2_PASSING      = 3   // Qualities below this start the affirmation over.
+
+	_DAY = 24 * time.Hour
+)
+
+// reviewState is the spaced repetition history of one affirmation.
+type reviewState struct {
+	Repetitions  int       // How many times in a row it has been known.
+	EaseFactor   float64   // How quickly the interval grows.
+	IntervalDays int       // The days until the next review.
+	Due          time.Time // When the affirmation should be reviewed next.
+}
+
+// review grades an affirmation with the SM-2 algorithm and schedules its next review.
+func (r reviewState) review(known bool, now time.Time) (next reviewState) {
+	next = r
+	if next.EaseFactor == 0 {
+		next.EaseFactor = _SM2_INITIAL_EASE
+	}
+
+	quality := _SM2_NOT_YET
+	if known {
+		quality = _SM2_KNOWN
+	}
+
+	if quality >= _SM2_PASSING {
+		// Known, push the next review further out.
+		switch next.Repetitions {
+		case 0:
+			next.IntervalDays = 1
+		case 1:
+			next.IntervalDays = 6
+		default:
+			next.IntervalDays = int(math.Round(float64(next.IntervalDays) * next.EaseFactor))
+		}
+		next.Repetitions++
+	} else {
+		// Not yet, review it again this session.
+		next.Repetitions = 0
+		next.IntervalDays = 0
+	}
+
+	// Adjust how easy the affirmation is.
+	miss := float64(5 - quality)
+	next.EaseFactor += 0.1 - miss*(0.08+miss*0.02)
+	if next.EaseFactor < _SM2_MINIMUM_EASE {
+		next.EaseFactor = _SM2_MINIMUM_EASE
+	}
+
+	next.Due = now.Add(time.Duration(next.IntervalDays) * _DAY)
+	return next
+}
+
+// reviewOf gets the review history of an affirmation, kept by its identity.
+// Review files from before identities kept it by message.
+func reviewOf(reviews map[string]reviewState, identity, message string) (review reviewState) {
+	if review, found := reviews[identity]; found {
+		return review
+	}
+	return reviews[message]
+}
+
+// spacedOrder orders affirmations for review, the most overdue first.
+// Affirmations never reviewed are due. If nothing is due, everything is shown soonest due first.
+func spacedOrder(affirmations []Affirmation, reviews map[string]reviewState, now time.Time) (indexes []int) {
+
+	// When each affirmation is due, never reviewed is due at the dawn of time.
+	dues := make([]time.Time, len(affirmations))
+	for i, identity := range identities(affirmations) {
+		dues[i] = reviewOf(reviews, identity, affirmations[i].Message).Due
+	}
+
+	// The due affirmations.
+	for i := range affirmations {
+		if !dues[i].After(now) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	// Keep the slide show running even if nothing is due.
+	if len(indexes) == 0 {
+		for i := range affirmations {
+			indexes = append(indexes, i)
+		}
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return dues[indexes[a]].Before(dues[indexes[b]])
+	})
+
+	return indexes
+}
+
+// reviewFilename is the spaced repetition review file for an affirmations file.
+func reviewFilename(affirmationFilename string) (filename string) {
+	return affirmationFilename + _REVIEW_FILE_SUFFIX
+}
+
+// loadReviews loads the spaced repetition history, keyed by affirmation identity.
+func loadReviews(filename string) (reviews map[string]reviewState, err error) {
+
+	// No file yet means nothing has been reviewed.
+	bytes, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return map[string]reviewState{}, nil
+	}
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	if err = json.Unmarshal(bytes, &reviews); err != nil {
+		return nil, Error(err)
+	}
+	if reviews == nil {
+		reviews = map[string]reviewState{}
+	}
+
+	return reviews, nil
+}
+
+// saveReviews saves the spaced repetition history.
+func saveReviews(filename string, reviews map[string]reviewState) (err error) {
+	bytes, err := json.MarshalIndent(reviews, "", "\t")
+	if err != nil {
+		return Error(err)
+	}
+	if err = ioutil.WriteFile(filename, bytes, 0644); err != nil {
+		return Error(err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package conditioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type SpacedSuite struct{}
+
+var _ = Suite(&SpacedSuite{})
+
+// Add the tests.
+
+func (s *SpacedSuite) Test_Review(c *C) {
+	now := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// Known three times, the interval grows.
+	r := reviewState{}.review(true, now)
+	c.Check(r.Repetitions, Equals, 1)
+	c.Check(r.IntervalDays, Equals, 1)
+	c.Check(r.EaseFactor, Equals, 2.5)
+	c.Check(r.Due, Equals, now.Add(_DAY))
+
+	r = r.review(true, now)
+	c.Check(r.Repetitions, Equals, 2)
+	c.Check(r.IntervalDays, Equals, 6)
+
+	r = r.review(true, now)
+	c.Check(r.Repetitions, Equals, 3)
+	c.Check(r.IntervalDays, Equals, 15)
+	c.Check(r.Due, Equals, now.Add(15*_DAY))
+
+	// Not yet starts over, due now, and is harder.
+	r = r.review(false, now)
+	c.Check(r.Repetitions, Equals, 0)
+	c.Check(r.IntervalDays, Equals, 0)
+	c.Check(r.Due, Equals, now)
+	c.Check(r.EaseFactor < 2.5, Equals, true)
+
+	// The ease never drops too far.
+	for i := 0; i < 10; i++ {
+		r = r.review(false, now)
+	}
+	c.Check(r.EaseFactor, Equals, _SM2_MINIMUM_EASE)
+}
+
+func (s *SpacedSuite) Test_SpacedOrder(c *C) {
+	now := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	affirmations := []Affirmation{{Message: "a"}, {Message: "b", ID: "b"}, {Message: "c"}, {Message: "d"}}
+	identity := identities(affirmations)
+
+	// Never reviewed is due first, then the most overdue, and future reviews wait.
+	reviews := map[string]reviewState{
+		identity[0]: {Due: now.Add(-time.Hour)},
+		identity[1]: {Due: now.Add(_DAY)},
+		identity[2]: {Due: now.Add(-2 * time.Hour)},
+	}
+	c.Check(spacedOrder(affirmations, reviews, now), DeepEquals, []int{3, 2, 0})
+
+	// Nothing due shows everything, soonest first.
+	reviews = map[string]reviewState{
+		identity[0]: {Due: now.Add(3 * _DAY)},
+		identity[1]: {Due: now.Add(_DAY)},
+		identity[2]: {Due: now.Add(4 * _DAY)},
+		identity[3]: {Due: now.Add(2 * _DAY)},
+	}
+	c.Check(spacedOrder(affirmations, reviews, now), DeepEquals, []int{1, 3, 0, 2})
+
+	// Reviews kept by message, from before identities, still count.
+	reviews = map[string]reviewState{
+		"a":         {Due: now.Add(_DAY)},
+		"b":         {Due: now.Add(_DAY)},
+		identity[2]: {Due: now.Add(_DAY)},
+	}
+	c.Check(spacedOrder(affirmations, reviews, now), DeepEquals, []int{3})
+
+	// An edited message with an id keeps its history.
+	affirmations[1].Message = "bee"
+	reviews = map[string]reviewState{identity[1]: {Due: now.Add(_DAY)}}
+	c.Check(spacedOrder(affirmations, reviews, now), DeepEquals, []int{0, 2, 3})
+}
+
+func (s *SpacedSuite) Test_LoadSaveReviews(c *C) {
+	dir, err := ioutil.TempDir("", "conditioning")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	filename := reviewFilename(filepath.Join(dir, "affirmations.txt"))
+	c.Check(filename, Equals, filepath.Join(dir, "affirmations.txt.review.json"))
+
+	// No file yet.
+	reviews, err := loadReviews(filename)
+	c.Assert(err, IsNil)
+	c.Check(reviews, DeepEquals, map[string]reviewState{})
+
+	// Round trip.
+	due := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	reviews["I am calm."] = reviewState{Repetitions: 2, EaseFactor: 2.6, IntervalDays: 6, Due: due}
+	c.Assert(saveReviews(filename, reviews), IsNil)
+	loaded, err := loadReviews(filename)
+	c.Assert(err, IsNil)
+	c.Check(loaded, DeepEquals, reviews)
+}
@@ -4,18 +4,8 @@ import (
 	"math/rand"
 	"sync"
 	"time"
-
-	"github.com/gotk3/gotk3/cairo"
-	"github.com/gotk3/gotk3/gdk"
-	"github.com/gotk3/gotk3/gtk"
 )
 
-type affirmationData struct {
-	affirmation  Affirmation   // The affirmation loaded from a file.
-	displayText  DisplayText   // The text prepared for rendering.
-	displayImage *DisplayImage // The image prepared for rendering, if there is one.
-}
-
 // System is the wrapper for data.
 type System struct {
 	// Mutex bookkeeping.
@@ -24,48 +14,186 @@ type System struct {
 	activeAffirmationIndex int  // The currently active affirmation.
 	displayBoth            bool // If false, display image only.
 	// Slide show.
-	slideShowRandom   bool // If true randomly scramble the order of slides.
-	slideShowTicker   *time.Ticker
-	slideShowDoneChan chan bool
-	slideShowIndexes  []int
-	slideShowI        int
-	// Cached slides.
-	cachedWidth  int
-	cachedHeight int
-	cachedSlides map[int]*gdk.Pixbuf
+	slideShowRandom    bool          // If true randomly scramble the order of slides.
+	slideShowSpaced    bool          // If true order slides by spaced repetition, overriding random.
+	slideShowRunning   bool          // If true the slide show is started, playing or paused.
+	slideShowPaused    bool          // If true the started slide show is holding on the current slide.
+	slideShowRemaining time.Duration // While paused, the time left on the current slide.
+	slideShowDeadline  time.Time     // While playing, when the current slide ends.
+	slideShowSpeed     int           // The speed as a percent of the configured timing.
+	slideShowPausedAt  time.Time     // While paused, when the pause started.
+	slideShowShown     int           // How many slides this session has shown.
+	slideShowEnds      time.Time     // When this session ends, if it is timed.
+	slideShowFinished  bool          // If true the session ended on its own.
+	slideShowTimer     Timer
+	slideShowDoneChan  chan bool
+	// Slide show order.
+	sequencer        Sequencer        // The order in use.
+	orderedSequencer Sequencer        // The order when neither random nor spaced, may be custom.
+	randomSequencer  Sequencer        // The order when random.
+	spacedSequencer  *spacedSequencer // The order when spaced.
+	// Presentation.
+	presenter Presenter     // Told about every change, if there is one.
+	clock     Clock         // Tells the time for the slide show.
+	preparer  SlidePreparer // Measures text and loads images, needed to load.
 	// Data.
 	config              Config
 	affirmationFilename string
 	imagePath           string
-	affirmations        []affirmationData
+	affirmations        []Slide
+	title               string                 // The title from the affirmations file.
+	problems            []string               // What went wrong with the last load, for showing on screen.
+	reviews             map[string]reviewState // Spaced repetition history, loaded when first needed.
+}
+
+// SystemOption customizes a new system.
+type SystemOption func(system *System)
+
+// WithSequencer installs a custom slide show order, used when the slide show is neither random nor spaced.
+func WithSequencer(sequencer Sequencer) SystemOption {
+	return func(system *System) {
+		system.orderedSequencer = sequencer
+	}
+}
+
+// WithPresenter installs a presenter to be told about every change to the system.
+func WithPresenter(presenter Presenter) SystemOption {
+	return func(system *System) {
+		system.presenter = presenter
+	}
+}
+
+// WithClock installs the clock the slide show is timed with.
+func WithClock(clock Clock) SystemOption {
+	return func(system *System) {
+		system.clock = clock
+	}
+}
+
+// WithSlidePreparer installs what measures text and loads images for display.
+func WithSlidePreparer(preparer SlidePreparer) SystemOption {
+	return func(system *System) {
+		system.preparer = preparer
+	}
 }
 
 // NewSystem creates a wellformed system for displaying
-func NewSystem(config Config, affirmationFilename, imagePath string) (system *System, err error) {
+func NewSystem(config Config, affirmationFilename, imagePath string, options ...SystemOption) (system *System, err error) {
 	if err = config.Validate(); err != nil {
 		return nil, err
 	}
-	return &System{
+	system = &System{
 		mux:                 &sync.Mutex{},
+		orderedSequencer:    NewOrderedSequencer(),
+		randomSequencer:     NewShuffledNoRepeatSequencer(),
+		spacedSequencer:     &spacedSequencer{},
+		clock:               NewRealClock(),
+		slideShowSpeed:      _SPEED_NORMAL,
 		config:              config,
 		affirmationFilename: affirmationFilename,
 		imagePath:           imagePath,
-	}, nil
+	}
+	for _, option := range options {
+		option(system)
+	}
+	system.sequencer = system.orderedSequencer
+	system.spacedSequencer.now = system.clock.Now
+	return system, nil
 }
 
 // RandomOnOff configures whether the slide show is ordered or random.
 func (s *System) RandomOnOff() {
 	s.mux.Lock()
+	defer s.present(EVENT_ORDER_CHANGED) // After unlocking.
 	defer s.mux.Unlock()
 
 	s.slideShowRandom = !s.slideShowRandom
+	s.chooseSequencer()
+}
+
+// SpacedOnOff configures whether the slide show uses spaced repetition.
+func (s *System) SpacedOnOff() (err error) {
+	s.mux.Lock()
+	defer s.present(EVENT_ORDER_CHANGED) // After unlocking.
+	defer s.mux.Unlock()
+
+	// The review history is needed before we can order anything.
+	if !s.slideShowSpaced && s.reviews == nil {
+		if s.reviews, err = loadReviews(reviewFilename(s.affirmationFilename)); err != nil {
+			return err
+		}
+		s.spacedSequencer.reviews = s.reviews
+	}
+
+	s.slideShowSpaced = !s.slideShowSpaced
+	s.chooseSequencer()
+
+	return nil
+}
+
+// chooseSequencer switches to the slide show order for the current settings, starting it fresh.
+func (s *System) chooseSequencer() {
+	switch {
+	case s.slideShowSpaced:
+		s.sequencer = s.spacedSequencer
+	case s.slideShowRandom:
+		s.sequencer = s.randomSequencer
+	default:
+		s.sequencer = s.orderedSequencer
+	}
+	s.sequencer.Reset(s.loadedAffirmations())
+}
+
+// loadedAffirmations gets the affirmations as loaded from the file.
+func (s *System) loadedAffirmations() (affirmations []Affirmation) {
+	for _, data := range s.affirmations {
+		affirmations = append(affirmations, data.Affirmation)
+	}
+	return affirmations
+}
+
+// moveTo makes an affirmation active, ignoring an index no affirmation has.
+func (s *System) moveTo(index int) {
+	if index < 0 || index > s.maxAffirmationIndex() || index == s.activeAffirmationIndex {
+		return
+	}
+	s.activeAffirmationIndex = index
+
+	// A paused slide show resumes with the whole time for the new slide.
+	if s.slideShowPaused {
+		s.slideShowRemaining = s.slideDuration()
+	}
+}
+
+// Grade records whether the current affirmation is known and moves to the next one when using spaced repetition.
+func (s *System) Grade(known bool) (err error) {
+	s.mux.Lock()
+	defer s.present(EVENT_SLIDE_CHANGED) // After unlocking.
+	defer s.mux.Unlock()
+
+	// Only spaced repetition keeps grades.
+	if !s.slideShowSpaced || len(s.affirmations) == 0 {
+		return nil
+	}
+
+	// Schedule the next review.
+	affirmations := s.loadedAffirmations()
+	identity := identities(affirmations)[s.activeAffirmationIndex]
+	message := affirmations[s.activeAffirmationIndex].Message
+	s.reviews[identity] = reviewOf(s.reviews, identity, message).review(known, s.clock.Now())
+	if err = saveReviews(reviewFilename(s.affirmationFilename), s.reviews); err != nil {
+		return err
+	}
+
+	s.nextSlide()
+	return nil
 }
 
 // getDisplayText returns whether the text should be rendered.
 func (s *System) getDisplayBoth() (displayBoth bool) {
 
 	// If slide show is running, always display the text.
-	if s.slideShowTicker != nil {
+	if s.slideShowRunning {
 		return true // Always display text in a slide show.
 	}
 
@@ -73,161 +201,209 @@ func (s *System) getDisplayBoth() (displayBoth bool) {
 }
 
 // Load loads all the affirmations and prepares them for display.
+// If the load fails, the affirmations already loaded stay. Either way, the problems can be shown with Problems.
 func (s *System) Load() (title string, err error) {
 	s.mux.Lock()
+	defer s.present(EVENT_SLIDES_LOADED) // After unlocking.
 	defer s.mux.Unlock()
 
-	// Load from the text file.
-	affirmations, title, err := LoadAffirmations(s.affirmationFilename)
+	title, diagnostics, err := s.load()
+
+	// Keep what went wrong for showing on screen.
+	s.problems = nil
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SEVERITY_ERROR {
+			s.problems = append(s.problems, diagnostic.String())
+		}
+	}
 	if err != nil {
-		return "", Error(err)
+		s.problems = append(s.problems, errorMessage(err))
+		return "", err
 	}
 
-	// Create a context of the proper dimensions for sizing everything.
-	surface := cairo.CreateImageSurface(cairo.FORMAT_ARGB32, int(s.config.ScreenWidth), int(s.config.ScreenHeight))
-	cr := cairo.Create(surface)
+	return title, nil
+}
 
-	// Prepare the affirmation data.
-	var affirmationDatas []affirmationData
-	for _, affirmation := range affirmations {
+// load loads all the affirmations and prepares them for display, keeping the active affirmation if it is still there.
+func (s *System) load() (title string, diagnostics []ParseDiagnostic, err error) {
 
-		// Prep the parts that must exist.
-		data := affirmationData{
-			affirmation: affirmation,
-			displayText: PrepareText(s.config, cr, affirmation.Message, affirmation.Text),
-		}
-
-		// Is there an image?
-		if affirmation.Image.Filename != "" {
-			displayImage, err := PrepareImage(s.config, cr, s.imagePath, affirmation.Image)
-			if err != nil {
-				return "", Error(err)
-			}
-			data.displayImage = &displayImage
-		}
+	// Nothing can be displayed without a way to prepare it.
+	if s.preparer == nil {
+		return "", nil, Errorf(`no slide preparer`)
+	}
 
-		// Add the affirmation to the affirmations in the system.
-		affirmationDatas = append(affirmationDatas, data)
+	// Load from the text file.
+	affirmations, title, diagnostics, err := LoadAffirmations(s.affirmationFilename)
+	if err != nil {
+		return "", nil, Error(err)
 	}
-	s.affirmations = affirmationDatas
 
-	// Keep the index in bounds.
-	if s.activeAffirmationIndex > s.maxAffirmationIndex() {
-		s.activeAffirmationIndex = s.maxAffirmationIndex()
+	// Report any problems in the file.
+	if err = reportDiagnostics(s.config, s.affirmationFilename, diagnostics); err != nil {
+		return "", diagnostics, err
 	}
 
-	// Create an index list for these affirmations we can shuffle.
-	s.slideShowIndexes = s.calculateSlideShowIndixes()
-	s.slideShowI = 0
+	// Prepare the affirmation data.
+	slides, err := PrepareSlides(s.preparer, s.config, s.imagePath, affirmations)
+	if err != nil {
+		return "", diagnostics, Error(err)
+	}
 
-	// Reset the rendered slide cache.
-	s.clearSlideCacheIfNecessary(0, 0) // Passing 0, 0 should trigger a cache clear.
+	// The affirmation on screen, to find again.
+	previous := s.loadedAffirmations()
+	var activeIdentity string
+	if len(previous) > 0 {
+		activeIdentity = identities(previous)[s.activeAffirmationIndex]
+	}
+	s.affirmations = slides
+	s.title = title
 
-	return title, nil
-}
+	// The review history may have been edited too.
+	if s.slideShowSpaced {
+		if s.reviews, err = loadReviews(reviewFilename(s.affirmationFilename)); err != nil {
+			return "", diagnostics, err
+		}
+		s.spacedSequencer.reviews = s.reviews
+	}
 
-// StartStopSlideShow starts a slide show or stops a running one.
-func (s *System) StartStopSlideShow(win *gtk.Window) (err error) {
-	s.mux.Lock()
-	defer s.mux.Unlock()
+	// Stay on the same affirmation if it is still there, otherwise keep the index in bounds.
+	s.keepActive(previous, activeIdentity)
 
-	// If there is no allocated slide show ticker, we need to start the slide show.
-	if s.slideShowTicker == nil {
+	return title, diagnostics, nil
+}
 
-		s.slideShowTicker = time.NewTicker(time.Duration(s.config.SleepMilli) * time.Millisecond)
-		s.slideShowDoneChan = make(chan bool)
+// keepActive follows the active affirmation to where it is in newly loaded affirmations, carrying on
+// through the slide show order without repeating what has already shown, if the order knows how.
+func (s *System) keepActive(previous []Affirmation, activeIdentity string) {
+	affirmations := s.loadedAffirmations()
 
-		// Start go routine that operates the slide show.
-		go func() {
-			for { // Infinite loop.
-				select {
+	if index, found := findIdentity(affirmations, activeIdentity); found {
+		s.activeAffirmationIndex = index
+	} else if s.activeAffirmationIndex > s.maxAffirmationIndex() {
+		s.activeAffirmationIndex = s.maxAffirmationIndex()
+	}
+	if s.activeAffirmationIndex < 0 {
+		s.activeAffirmationIndex = 0
+	}
 
-				// A tick from teh ticker.
-				case <-s.slideShowTicker.C:
-					s.Random() // Pick a new slide.
-					win.QueueDraw()
+	// Carry the slide show order over, or start it over for these affirmations.
+	if reloader, ok := s.sequencer.(reloader); ok {
+		reloader.reload(previous, affirmations, s.activeAffirmationIndex)
+	} else {
+		s.sequencer.Reset(affirmations)
+	}
+}
 
-				// A stop command.
-				case <-s.slideShowDoneChan:
-					return // kill the goroutine.
-				}
-			}
-		}()
+// Title gets the title from the affirmations file.
+func (s *System) Title() (title string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
 
-	} else {
-		// There is an allocated slide show ticker, we need to end the slide show.
-		s.slideShowTicker.Stop()    // Stop ticker.
-		s.slideShowDoneChan <- true // Escape the golang function responding to ticks.
+	return s.title
+}
 
-		s.slideShowTicker = nil
-		s.slideShowDoneChan = nil
-	}
+// Problems gets what went wrong with the last load, if anything.
+func (s *System) Problems() (problems []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
 
-	return nil
+	return append([]string{}, s.problems...)
 }
 
 // Random picks a random affirmation and makes it active.
 func (s *System) Random() (err error) {
 	s.mux.Lock()
+	defer s.present(EVENT_SLIDE_CHANGED) // After unlocking.
 	defer s.mux.Unlock()
 
-	// Can only do random if there are a few things that we could pick.
-	if s.maxAffirmationIndex() > 0 {
-
-		// Have we gone through the slide show?
-		if s.slideShowI >= len(s.slideShowIndexes)-1 {
-
-			// Restart the slide show.
-			s.slideShowI = 0
-			s.slideShowIndexes = s.calculateSlideShowIndixes()
+	s.nextSlide()
+	return nil
+}
 
-			// Now set the random slide show screen.
-			s.activeAffirmationIndex = s.slideShowIndexes[s.slideShowI]
+// nextSlide moves to the next slide of the slide show.
+func (s *System) nextSlide() {
 
-		} else {
-			// Go to next slide of the slide show.
-			s.slideShowI++
-			s.activeAffirmationIndex = s.slideShowIndexes[s.slideShowI]
-		}
+	// Can only move if there are a few things that we could pick.
+	if s.maxAffirmationIndex() > 0 {
+		s.moveTo(s.sequencer.Next(s.activeAffirmationIndex))
 	}
-
-	return nil
 }
 
-// calculateSlideShowIndixes figures out the indixes for the slide show.
-func (s *System) calculateSlideShowIndixes() (slideShowIndexes []int) {
+// weightedShuffle randomly orders indexes, each appearing as many times as its weight.
+// No index immediately follows itself (or the previous index) unless the weights leave no other choice.
+func weightedShuffle(weights []int, previous int) (indexes []int) {
 
-	// Are we random?
-	if s.slideShowRandom {
+	// How many of each index are left to place.
+	counts := append([]int{}, weights...)
+	remaining := 0
+	for _, count := range counts {
+		remaining += count
+	}
 
-		// Get random slide show.
-		slideShowIndexes = rand.Perm(len(s.affirmations))
-		// If we are currently looking at the new first affirmation, move that affirmation to the end.
-		if s.activeAffirmationIndex == slideShowIndexes[s.slideShowI] {
-			slideShowIndexes = append(slideShowIndexes[1:], slideShowIndexes[:1]...)
-		}
+	for ; remaining > 0; remaining-- {
 
-	} else {
+		// If an index fills more than half of the remaining places, it must go now or it will end up repeating.
+		pick := -1
+		for i, count := range counts {
+			if i != previous && count*2 > remaining {
+				pick = i
+			}
+		}
 
-		// Get ordered slide show.
-		var orderedIndexes []int
-		for i := 0; i < len(s.affirmations); i++ {
-			orderedIndexes = append(orderedIndexes, i)
+		// Otherwise pick at random from the indexes that are not a repeat.
+		if pick == -1 {
+			total := 0
+			for i, count := range counts {
+				if i != previous {
+					total += count
+				}
+			}
+			if total == 0 {
+				pick = previous // Only repeats are left.
+			} else {
+				n := rand.Intn(total)
+				for i, count := range counts {
+					if i == previous {
+						continue
+					}
+					if n < count {
+						pick = i
+						break
+					}
+					n -= count
+				}
+			}
 		}
-		slideShowIndexes = orderedIndexes
+
+		indexes = append(indexes, pick)
+		counts[pick]--
+		previous = pick
 	}
 
-	return slideShowIndexes
+	return indexes
 }
 
-// Left moves the pointer to displayed affirmation towards the top of the file.
+// Left moves the pointer to displayed affirmation towards the top of the file, or back through a started slide show.
 func (s *System) Left() (err error) {
 	s.mux.Lock()
+	defer s.present(EVENT_SLIDE_CHANGED) // After unlocking.
 	defer s.mux.Unlock()
+	s.slideShowFinished = false // Back to the slides.
+
+	// Nothing to move through, like after reloading a file with no affirmations.
+	if len(s.affirmations) == 0 {
+		return nil
+	}
+
+	// A slide show shows the whole slide, so move a whole slide back through its order.
+	if s.slideShowRunning {
+		s.skipTo(s.sequencer.Prev(s.activeAffirmationIndex))
+		return nil
+	}
 
 	// If we are currently displaying text and their is an image, then we should
 	// no longer display the text, navigating the slide in two steps.
-	if s.displayBoth && s.affirmations[s.activeAffirmationIndex].displayImage != nil {
+	if s.displayBoth && s.affirmations[s.activeAffirmationIndex].DisplayImage != nil {
 		s.displayBoth = false // We've navigated to the beginning of a slide, show the image alone.
 		return
 	}
@@ -241,10 +417,23 @@ func (s *System) Left() (err error) {
 	return nil
 }
 
-// Right moves the pointer to displayed affirmation towards the bottom of the file.
+// Right moves the pointer to displayed affirmation towards the bottom of the file, or on through a started slide show.
 func (s *System) Right() (err error) {
 	s.mux.Lock()
+	defer s.present(EVENT_SLIDE_CHANGED) // After unlocking.
 	defer s.mux.Unlock()
+	s.slideShowFinished = false // Back to the slides.
+
+	// Nothing to move through, like after reloading a file with no affirmations.
+	if len(s.affirmations) == 0 {
+		return nil
+	}
+
+	// A slide show shows the whole slide, so move a whole slide on through its order.
+	if s.slideShowRunning {
+		s.skipTo(s.sequencer.Next(s.activeAffirmationIndex))
+		return nil
+	}
 
 	// If we are currently not displaying text, just display it.
 	if !s.displayBoth {
@@ -259,7 +448,7 @@ func (s *System) Right() (err error) {
 	}
 
 	// Does this new slide have an image?
-	if s.affirmations[s.activeAffirmationIndex].displayImage != nil {
+	if s.affirmations[s.activeAffirmationIndex].DisplayImage != nil {
 		s.displayBoth = false // We've navigated to the beginning of a slide, show the image alone.
 	}
 
@@ -280,48 +469,12 @@ func (s *System) DisplayTextImage() (affirmationIndex int, displayText DisplayTe
 		return 0, DisplayText{}, nil, false, false
 	}
 
-	return s.activeAffirmationIndex, s.affirmations[s.activeAffirmationIndex].displayText, s.affirmations[s.activeAffirmationIndex].displayImage, s.getDisplayBoth(), true
+	return s.activeAffirmationIndex, s.affirmations[s.activeAffirmationIndex].DisplayText, s.affirmations[s.activeAffirmationIndex].DisplayImage, s.getDisplayBoth(), true
 }
 
-// CacheSlide caches a slide for quick rendering.
-func (s *System) CacheSlide(affirmationIndex, winWidth, winHeight int, pixbuf *gdk.Pixbuf) {
-	s.mux.Lock()
-	defer s.mux.Unlock()
-
-	// Clear the cache if our window size has changed.
-	s.clearSlideCacheIfNecessary(winWidth, winHeight)
-
-	// Cache the slide.
-	s.cachedSlides[affirmationIndex] = pixbuf
-}
-
-// GetCachedSlide gets a cached slide if there is one for quick rendering.
-func (s *System) GetCachedSlide(affirmationIndex, winWidth, winHeight int) (pixbuf *gdk.Pixbuf, found bool) {
-	s.mux.Lock()
-	defer s.mux.Unlock()
-
-	// Clear the cache if our window size has changed.
-	s.clearSlideCacheIfNecessary(winWidth, winHeight)
-
-	// Get the cached value.
-	pixbuf, found = s.cachedSlides[affirmationIndex]
-	if !found {
-		return nil, false
-	}
-
-	return pixbuf, true
-}
-
-// clearSlideCacheIfNecessary clears the cache of prerendered slides if width or height changed
-func (s *System) clearSlideCacheIfNecessary(winWidth, winHeight int) {
-
-	// Is this still the proper cache.
-	if winWidth == s.cachedWidth && winHeight == s.cachedHeight {
-		// The cache is still good.
-		return
+// present tells the presenter about a change. The system must not be locked.
+func (s *System) present(event Event) {
+	if s.presenter != nil {
+		s.presenter.StateChanged(event)
 	}
-
-	s.cachedWidth = winWidth
-	s.cachedHeight = winHeight
-	s.cachedSlides = map[int]*gdk.Pixbuf{}
 }
@@ -0,0 +1,724 @@
+package conditioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type SystemSuite struct{}
+
+var _ = Suite(&SystemSuite{})
+
+// A valid config for systems under test.
+var testSystemConfig = Config{SleepMilli: 60000, ScreenWidth: 1, ScreenHeight: 1, FontFace: "Georgia", FontSize: 1, BlackOutlineScale: 0.5, WhiteOutlineScale: 0.5}
+
+// recordingPresenter remembers what it was told, for testing.
+type recordingPresenter struct {
+	mux    sync.Mutex
+	events []Event
+}
+
+func (r *recordingPresenter) StateChanged(event Event) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Wait waits for the presenter to be told about an event.
+func (r *recordingPresenter) Wait(c *C, event Event) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		r.mux.Lock()
+		for i, told := range r.events {
+			if told == event {
+				r.events = r.events[i+1:]
+				r.mux.Unlock()
+				return
+			}
+		}
+		r.mux.Unlock()
+	}
+	c.Fatalf("presenter never told about event %d", event)
+}
+
+// Events gets and forgets what the presenter was told.
+func (r *recordingPresenter) Events() (events []Event) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	events, r.events = r.events, nil
+	return events
+}
+
+// testPreparer prepares slides without measuring text or loading images, for testing.
+type testPreparer struct{}
+
+func (testPreparer) PrepareText(config Config, message string, textProperties TextProperties) (displayText DisplayText) {
+	return StyleText(config, message, textProperties)
+}
+
+func (testPreparer) PrepareImage(config Config, imagePath string, affirmationImage AffirmationImage) (displayImage DisplayImage, err error) {
+	return DisplayImage{Filename: imagePath + affirmationImage.Filename}, nil
+}
+
+// Add the tests.
+
+func (s *SystemSuite) Test_AffirmationDuration(c *C) {
+	fixed := Config{SleepMilli: 3000}
+	reading := Config{SleepMilli: 3000, WordsPerMinute: 120, MinSleepMilli: 1500, MaxSleepMilli: 6000}
+	unlimited := Config{SleepMilli: 3000, WordsPerMinute: 120, MinSleepMilli: 500}
+	tests := []struct {
+		config      Config
+		affirmation Affirmation
+		duration    time.Duration
+	}{
+		// The configured time.
+		{fixed, Affirmation{Message: "one two three four five"}, 3 * time.Second},
+
+		// An explicit time always wins.
+		{fixed, Affirmation{Message: "one", Duration: 8 * time.Second}, 8 * time.Second},
+		{reading, Affirmation{Message: "one", Duration: 8 * time.Second}, 8 * time.Second},
+
+		// Two words a second, within the limits.
+		{reading, Affirmation{Message: "one two three four five"}, 2500 * time.Millisecond},
+		{reading, Affirmation{Message: "one"}, 1500 * time.Millisecond},
+		{reading, Affirmation{Message: "one two three four five six seven eight nine ten eleven twelve thirteen"}, 6 * time.Second},
+		{unlimited, Affirmation{Message: "one two three four five six seven eight nine ten eleven twelve thirteen"}, 6500 * time.Millisecond},
+		{unlimited, Affirmation{Message: ""}, 500 * time.Millisecond},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+		c.Check(affirmationDuration(test.config, test.affirmation), Equals, test.duration, comment)
+	}
+}
+
+func (s *SystemSuite) Test_WeightedShuffle(c *C) {
+	tests := []struct {
+		weights  []int
+		previous int
+		repeats  int // Repeats the weights can't avoid.
+	}{
+		{[]int{}, 0, 0},
+		{[]int{1}, 0, 1},
+		{[]int{1, 1, 1, 1}, 2, 0},
+		{[]int{3, 1, 1}, 1, 0},
+		{[]int{3, 1, 1}, 0, 1},
+		{[]int{2, 1}, 1, 0},
+		{[]int{1, 5, 2, 1}, 3, 0},
+		{[]int{3, 1}, 1, 1},
+	}
+	for i, test := range tests {
+		comment := Commentf("Case %v: %v", i, test)
+
+		// Shuffling is random so try it many times.
+		for try := 0; try < 100; try++ {
+			indexes := weightedShuffle(test.weights, test.previous)
+
+			// Every index appears as many times as its weight.
+			counts := make([]int, len(test.weights))
+			for _, index := range indexes {
+				counts[index]++
+			}
+			c.Assert(counts, DeepEquals, test.weights, comment)
+
+			// Nothing repeats unless it has to.
+			repeats := 0
+			previous := test.previous
+			for _, index := range indexes {
+				if index == previous {
+					repeats++
+				}
+				previous = index
+			}
+			c.Assert(repeats, Equals, test.repeats, comment)
+		}
+	}
+}
+
+// reverseSequencer is a custom slide show order for testing.
+type reverseSequencer struct {
+	count  int
+	resets int
+}
+
+func (r *reverseSequencer) Reset(affirmations []Affirmation) {
+	r.count = len(affirmations)
+	r.resets++
+}
+
+func (r *reverseSequencer) Next(current int) (index int) {
+	return (current - 1 + r.count) % r.count
+}
+
+func (r *reverseSequencer) Prev(current int) (index int) {
+	return (current + 1) % r.count
+}
+
+func (s *SystemSuite) Test_WithSequencer(c *C) {
+	custom := &reverseSequencer{}
+	system, err := NewSystem(testSystemConfig, "affirmations.txt", "images/", WithSequencer(custom))
+	c.Assert(err, IsNil)
+	c.Check(system.sequencer, Equals, Sequencer(custom))
+
+	// Three affirmations without images.
+	system.affirmations = []Slide{{}, {}, {}}
+	system.displayBoth = true
+	system.chooseSequencer()
+	c.Check(custom.resets, Equals, 1)
+
+	// The slide show follows the custom order.
+	c.Assert(system.Random(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 2)
+	c.Assert(system.Random(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 1)
+
+	// Outside a slide show the arrows follow the file.
+	c.Assert(system.Right(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 2)
+	c.Assert(system.Left(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 1)
+
+	// In a slide show the arrows follow the custom order.
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	c.Assert(system.Right(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 0)
+	c.Assert(system.Left(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 1)
+	c.Assert(system.Left(), IsNil)
+	c.Check(system.activeAffirmationIndex, Equals, 2)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+
+	// Random replaces the custom order until it is turned off.
+	system.RandomOnOff()
+	c.Check(system.sequencer, Equals, system.randomSequencer)
+	system.RandomOnOff()
+	c.Check(system.sequencer, Equals, Sequencer(custom))
+	c.Check(custom.resets, Equals, 2)
+}
+
+func (s *SystemSuite) Test_Navigation(c *C) {
+	presenter := &recordingPresenter{}
+	system, err := NewSystem(testSystemConfig, "affirmations.txt", "images/", WithPresenter(presenter))
+	c.Assert(err, IsNil)
+
+	// An image slide between two text slides.
+	system.affirmations = []Slide{{}, {DisplayImage: &DisplayImage{}}, {}}
+	system.displayBoth = true
+	system.chooseSequencer()
+
+	// Tracks the active slide and whether its text shows.
+	check := func(index int, displayBoth bool) {
+		affirmationIndex, _, _, both, found := system.DisplayTextImage()
+		c.Check(found, Equals, true)
+		c.Check(affirmationIndex, Equals, index)
+		c.Check(both, Equals, displayBoth)
+	}
+	check(0, true)
+
+	// Right reveals an image slide in two steps.
+	c.Assert(system.Right(), IsNil)
+	check(1, false)
+	c.Assert(system.Right(), IsNil)
+	check(1, true)
+	c.Assert(system.Right(), IsNil)
+	check(2, true)
+	c.Assert(system.Right(), IsNil)
+	check(0, true)
+
+	// Left hides the text of an image slide before moving on.
+	c.Assert(system.Left(), IsNil)
+	check(2, true)
+	c.Assert(system.Left(), IsNil)
+	check(1, true)
+	c.Assert(system.Left(), IsNil)
+	check(1, false)
+	c.Assert(system.Left(), IsNil)
+	check(0, true)
+
+	c.Check(presenter.Events(), DeepEquals, []Event{
+		EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED,
+		EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED,
+	})
+
+	// A running slide show always shows the text, and leaves it showing when stopped.
+	c.Assert(system.Right(), IsNil)
+	check(1, false)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	check(1, true)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	check(1, true)
+
+	// In a slide show the arrows move a whole slide, image and text together.
+	c.Assert(system.Left(), IsNil)
+	check(1, false)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	c.Assert(system.Right(), IsNil)
+	check(2, true)
+	c.Assert(system.Left(), IsNil)
+	check(1, true)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+
+	system.RandomOnOff()
+	c.Check(presenter.Events(), DeepEquals, []Event{
+		EVENT_SLIDE_CHANGED, EVENT_SLIDE_SHOW_STARTED, EVENT_SLIDE_SHOW_STOPPED,
+		EVENT_SLIDE_CHANGED, EVENT_SLIDE_SHOW_STARTED, EVENT_SLIDE_CHANGED, EVENT_SLIDE_CHANGED, EVENT_SLIDE_SHOW_STOPPED,
+		EVENT_ORDER_CHANGED,
+	})
+
+	// Nothing loaded.
+	empty, err := NewSystem(testSystemConfig, "affirmations.txt", "images/")
+	c.Assert(err, IsNil)
+	_, _, _, _, found := empty.DisplayTextImage()
+	c.Check(found, Equals, false)
+}
+
+func (s *SystemSuite) Test_SlideShowTiming(c *C) {
+	presenter := &recordingPresenter{}
+	clock := NewManualClock(time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC))
+	system, err := NewSystem(testSystemConfig, "affirmations.txt", "images/", WithPresenter(presenter), WithClock(clock))
+	c.Assert(err, IsNil)
+
+	// Three slides, the middle one lingering.
+	system.affirmations = []Slide{
+		{Affirmation: Affirmation{Duration: time.Second}},
+		{Affirmation: Affirmation{Duration: 5 * time.Second}},
+		{Affirmation: Affirmation{Duration: time.Second}},
+	}
+	system.chooseSequencer()
+	active := func() (index int) {
+		index, _, _, _, _ = system.DisplayTextImage()
+		return index
+	}
+
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+
+	// The first slide moves on after its own time.
+	clock.Advance(999 * time.Millisecond)
+	c.Check(active(), Equals, 0)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 1)
+
+	// The middle slide lingers.
+	clock.Advance(4 * time.Second)
+	c.Check(active(), Equals, 1)
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 2)
+
+	// Round to the start again.
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 0)
+
+	// Random reshuffles at the end of every cycle, never repeating a slide.
+	system.RandomOnOff()
+	previous := active()
+	for cycle := 0; cycle < 10; cycle++ {
+		seen := map[int]bool{}
+		for i := 0; i < 3; i++ {
+			clock.Advance(5 * time.Second)
+			presenter.Wait(c, EVENT_SLIDE_CHANGED)
+			c.Assert(active(), Not(Equals), previous)
+			previous = active()
+			seen[previous] = true
+		}
+		c.Assert(len(seen), Equals, 3)
+	}
+
+	// Stopped slide shows stay put.
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	clock.Advance(time.Hour)
+	c.Check(active(), Equals, previous)
+}
+
+func (s *SystemSuite) Test_PauseResume(c *C) {
+	presenter := &recordingPresenter{}
+	clock := NewManualClock(time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC))
+	system, err := NewSystem(testSystemConfig, "affirmations.txt", "images/", WithPresenter(presenter), WithClock(clock))
+	c.Assert(err, IsNil)
+
+	// Three slides, the middle one lingering.
+	system.affirmations = []Slide{
+		{Affirmation: Affirmation{Duration: time.Second}},
+		{Affirmation: Affirmation{Duration: 5 * time.Second}},
+		{Affirmation: Affirmation{Duration: time.Second}},
+	}
+	system.chooseSequencer()
+	active := func() (index int) {
+		index, _, _, _, _ = system.DisplayTextImage()
+		return index
+	}
+
+	// Nothing to pause until started.
+	c.Assert(system.Pause(), IsNil)
+	c.Check(system.IsPlaying(), Equals, false)
+	c.Check(system.IsPaused(), Equals, false)
+	c.Check(presenter.Events(), HasLen, 0)
+
+	// Resume starts a stopped slide show.
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+	c.Check(system.IsPlaying(), Equals, true)
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 1)
+
+	// Pause part way through the lingering slide.
+	clock.Advance(2 * time.Second)
+	c.Assert(system.Pause(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_PAUSED)
+	c.Check(system.IsPlaying(), Equals, false)
+	c.Check(system.IsPaused(), Equals, true)
+
+	// Pausing again changes nothing.
+	c.Assert(system.Pause(), IsNil)
+	c.Check(presenter.Events(), HasLen, 0)
+
+	// A paused slide show stays put, still showing the text.
+	clock.Advance(time.Hour)
+	c.Check(active(), Equals, 1)
+	_, _, _, displayBoth, _ := system.DisplayTextImage()
+	c.Check(displayBoth, Equals, true)
+
+	// Resuming keeps the time left on the slide.
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_RESUMED)
+	c.Check(system.IsPlaying(), Equals, true)
+
+	// Resuming again changes nothing.
+	c.Assert(system.Resume(), IsNil)
+	c.Check(presenter.Events(), HasLen, 0)
+	clock.Advance(3*time.Second - time.Millisecond)
+	c.Check(active(), Equals, 1)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 2)
+
+	// Navigating while paused gives the new slide its whole time.
+	c.Assert(system.Pause(), IsNil)
+	c.Assert(system.Left(), IsNil)
+	c.Check(active(), Equals, 1)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_RESUMED)
+	clock.Advance(5*time.Second - time.Millisecond)
+	c.Check(active(), Equals, 1)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 2)
+
+	// Going forward while paused does too.
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 0)
+	clock.Advance(500 * time.Millisecond)
+	c.Assert(system.Pause(), IsNil)
+	c.Assert(system.Right(), IsNil)
+	c.Check(active(), Equals, 1)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_RESUMED)
+	clock.Advance(5*time.Second - time.Millisecond)
+	c.Check(active(), Equals, 1)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 2)
+
+	// Navigating while playing gives the new slide its whole time.
+	clock.Advance(500 * time.Millisecond)
+	c.Assert(system.Left(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 1)
+	clock.Advance(5*time.Second - time.Millisecond)
+	c.Check(active(), Equals, 1)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 2)
+
+	// Stopping forgets the pause.
+	c.Assert(system.Pause(), IsNil)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	c.Check(system.IsPlaying(), Equals, false)
+	c.Check(system.IsPaused(), Equals, false)
+}
+
+func (s *SystemSuite) Test_Speed(c *C) {
+	presenter := &recordingPresenter{}
+	clock := NewManualClock(time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC))
+	system, err := NewSystem(testSystemConfig, "affirmations.txt", "images/", WithPresenter(presenter), WithClock(clock))
+	c.Assert(err, IsNil)
+
+	// Two slides, shown for the configured minute each.
+	system.affirmations = []Slide{{}, {}}
+	system.chooseSequencer()
+	active := func() (index int) {
+		index, _, _, _, _ = system.DisplayTextImage()
+		return index
+	}
+	speed := func(percent int, interval time.Duration) {
+		obtainedPercent, obtainedInterval := system.Speed()
+		c.Check(obtainedPercent, Equals, percent)
+		c.Check(obtainedInterval, Equals, interval)
+	}
+	speed(100, time.Minute)
+
+	// Steps through the speeds, stopping at the ends.
+	c.Assert(system.SpeedUp(), IsNil)
+	speed(150, 40*time.Second)
+	c.Assert(system.SlowDown(), IsNil)
+	c.Assert(system.SlowDown(), IsNil)
+	speed(75, 80*time.Second)
+	for i := 0; i < 10; i++ {
+		c.Assert(system.SlowDown(), IsNil)
+	}
+	speed(25, 4*time.Minute)
+	for i := 0; i < 10; i++ {
+		c.Assert(system.SpeedUp(), IsNil)
+	}
+	speed(400, 15*time.Second)
+	presenter.Wait(c, EVENT_SPEED_CHANGED)
+
+	// Back to normal and start the slide show.
+	for i := 0; i < 4; i++ {
+		c.Assert(system.SlowDown(), IsNil)
+	}
+	speed(100, time.Minute)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+
+	// Doubling the speed half way through a slide halves the time left.
+	clock.Advance(30 * time.Second)
+	c.Assert(system.SpeedUp(), IsNil)
+	c.Assert(system.SpeedUp(), IsNil)
+	presenter.Events()
+	clock.Advance(15*time.Second - time.Millisecond)
+	c.Check(active(), Equals, 0)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 1)
+
+	// The next slide shows for the faster time.
+	clock.Advance(30 * time.Second)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 0)
+
+	// Paused time left is rescaled too.
+	clock.Advance(10 * time.Second)
+	c.Assert(system.Pause(), IsNil)
+	c.Assert(system.SlowDown(), IsNil)
+	c.Assert(system.SlowDown(), IsNil)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_RESUMED)
+	clock.Advance(40*time.Second - time.Millisecond)
+	c.Check(active(), Equals, 0)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(), Equals, 1)
+}
+
+func (s *SystemSuite) Test_SaveSpeed(c *C) {
+	dir, err := ioutil.TempDir("", "conditioning")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "config.json")
+	c.Assert(ioutil.WriteFile(filename, []byte(`{"SleepMilli": 60000, "FontSize": 1}`), 0644), IsNil)
+
+	// Settings from the command line, or left to their defaults, are not in the file.
+	config := testSystemConfig
+	config.Kiosk = true
+	config.StopAfterCycles = 3
+	system, err := NewSystem(config, "affirmations.txt", "images/")
+	c.Assert(err, IsNil)
+
+	// Only the timing is saved, the file keeping just what it had.
+	c.Assert(system.SpeedUp(), IsNil)
+	c.Assert(system.SaveSpeed(filename), IsNil)
+	contents, err := ioutil.ReadFile(filename)
+	c.Assert(err, IsNil)
+	c.Check(string(contents), Equals, "{\n\t\"FontSize\": 1,\n\t\"SleepMilli\": 40000\n}\n")
+
+	// Reading speed timing is saved too.
+	config.WordsPerMinute, config.MinSleepMilli = 120, 1500
+	system, err = NewSystem(config, "affirmations.txt", "images/")
+	c.Assert(err, IsNil)
+	c.Assert(system.SlowDown(), IsNil)
+	c.Assert(system.SaveSpeed(filename), IsNil)
+	saved, err := LoadConfig(filename)
+	c.Assert(err, IsNil)
+	c.Check(saved, DeepEquals, Config{SleepMilli: 80000, FontSize: 1, WordsPerMinute: 90, MinSleepMilli: 2000})
+}
+
+func (s *SystemSuite) Test_SpeedConfig(c *C) {
+	fixed := Config{SleepMilli: 3000}
+	reading := Config{SleepMilli: 3000, WordsPerMinute: 120, MinSleepMilli: 1500, MaxSleepMilli: 6000}
+	tests := []struct {
+		config  Config
+		percent int
+		sped    Config
+	}{
+		{fixed, 100, fixed},
+		{fixed, 200, Config{SleepMilli: 1500}},
+		{fixed, 75, Config{SleepMilli: 4000}},
+		{Config{SleepMilli: 1}, 400, Config{SleepMilli: 1}},
+		{reading, 50, Config{SleepMilli: 6000, WordsPerMinute: 60, MinSleepMilli: 3000, MaxSleepMilli: 12000}},
+		{reading, 150, Config{SleepMilli: 2000, WordsPerMinute: 180, MinSleepMilli: 1000, MaxSleepMilli: 4000}},
+		{Config{SleepMilli: 3000, WordsPerMinute: 3}, 25, Config{SleepMilli: 12000, WordsPerMinute: 1}},
+	}
+	for i, test := range tests {
+		c.Check(speedConfig(test.config, test.percent), DeepEquals, test.sped, Commentf("Case %v: %v", i, test))
+	}
+}
+
+func (s *SystemSuite) Test_SlideShowEnd(c *C) {
+	start := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// A system of three one second slides, ending as configured.
+	newSystem := func(config Config) (system *System, presenter *recordingPresenter, clock *ManualClock) {
+		presenter = &recordingPresenter{}
+		clock = NewManualClock(start)
+		system, err := NewSystem(config, "affirmations.txt", "images/", WithPresenter(presenter), WithClock(clock))
+		c.Assert(err, IsNil)
+		system.affirmations = []Slide{
+			{Affirmation: Affirmation{Duration: time.Second}},
+			{Affirmation: Affirmation{Duration: time.Second}},
+			{Affirmation: Affirmation{Duration: time.Second}},
+		}
+		system.chooseSequencer()
+		return system, presenter, clock
+	}
+	active := func(system *System) (index int) {
+		index, _, _, _, _ = system.DisplayTextImage()
+		return index
+	}
+
+	// Two full cycles.
+	config := testSystemConfig
+	config.StopAfterCycles = 2
+	system, presenter, clock := newSystem(config)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+	for _, index := range []int{1, 2, 0, 1, 2} {
+		clock.Advance(time.Second)
+		presenter.Wait(c, EVENT_SLIDE_CHANGED)
+		c.Check(active(system), Equals, index)
+	}
+	c.Check(system.Finished(), Equals, false)
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_ENDED)
+	c.Check(active(system), Equals, 2)
+	c.Check(system.Finished(), Equals, true)
+	c.Check(system.IsPlaying(), Equals, false)
+
+	// Nothing more happens.
+	clock.Advance(time.Hour)
+	c.Check(active(system), Equals, 2)
+
+	// Navigating goes back to the slides, starting again starts a new session.
+	c.Assert(system.Right(), IsNil)
+	c.Check(system.Finished(), Equals, false)
+	c.Check(active(system), Equals, 0)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+	c.Check(system.IsPlaying(), Equals, true)
+	c.Assert(system.StartStopSlideShow(), IsNil)
+
+	// A random cycle counts the weights.
+	system, presenter, clock = newSystem(config)
+	system.affirmations[0].Affirmation.Weight = 2
+	system.RandomOnOff()
+	c.Check(system.cycleLength(), Equals, 4)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+	for i := 0; i < 7; i++ {
+		clock.Advance(time.Second)
+		presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	}
+	c.Check(system.Finished(), Equals, false)
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_ENDED)
+	c.Check(system.Finished(), Equals, true)
+
+	// A timed session, ending part way through a slide, not counting a pause.
+	config = testSystemConfig
+	config.StopAfterMilli = 2500
+	system, presenter, clock = newSystem(config)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_STARTED)
+	clock.Advance(time.Second)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	clock.Advance(500 * time.Millisecond)
+	c.Assert(system.Pause(), IsNil)
+	clock.Advance(time.Hour)
+	c.Assert(system.Resume(), IsNil)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_RESUMED)
+	clock.Advance(500 * time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_CHANGED)
+	c.Check(active(system), Equals, 2)
+	clock.Advance(500*time.Millisecond - time.Millisecond)
+	c.Check(system.Finished(), Equals, false)
+	clock.Advance(time.Millisecond)
+	presenter.Wait(c, EVENT_SLIDE_SHOW_ENDED)
+	c.Check(active(system), Equals, 2)
+	c.Check(system.Finished(), Equals, true)
+}
+
+func (s *SystemSuite) Test_KeepActive(c *C) {
+	dir, err := ioutil.TempDir("", "conditioning")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	affirmationFilename := filepath.Join(dir, "affirmations.txt")
+
+	system, err := NewSystem(testSystemConfig, affirmationFilename, filepath.Join(dir, "images")+"/", WithSlidePreparer(testPreparer{}))
+	c.Assert(err, IsNil)
+	load := func(lines string) {
+		c.Assert(ioutil.WriteFile(affirmationFilename, []byte(lines), 0644), IsNil)
+		_, err := system.Load()
+		c.Assert(err, IsNil)
+	}
+	active := func() (message string) {
+		return system.affirmations[system.activeAffirmationIndex].Affirmation.Message
+	}
+
+	load("I am calm.\nI am kind.\nI am brave. [#brave]")
+	system.activeAffirmationIndex = 1
+
+	// A line inserted above stays on the same affirmation.
+	load("I am new.\nI am calm.\nI am kind.\nI am brave. [#brave]")
+	c.Check(system.activeAffirmationIndex, Equals, 2)
+	c.Check(active(), Equals, "I am kind.")
+
+	// An id follows an edited message.
+	system.activeAffirmationIndex = 3
+	load("I am brave and bold. [#brave]\nI am calm.")
+	c.Check(system.activeAffirmationIndex, Equals, 0)
+	c.Check(active(), Equals, "I am brave and bold.")
+
+	// A removed affirmation keeps the index in bounds.
+	system.activeAffirmationIndex = 1
+	load("I am new.")
+	c.Check(system.activeAffirmationIndex, Equals, 0)
+
+	// A repeated message stays on the same copy.
+	load("I am kind.\nI am calm.\nI am kind.")
+	system.activeAffirmationIndex = 2
+	load("I am new.\nI am kind.\nI am calm.\nI am kind.")
+	c.Check(system.activeAffirmationIndex, Equals, 3)
+
+	// Saving a file with no affirmations leaves nothing to navigate.
+	load("// Nothing yet.")
+	c.Check(system.affirmations, HasLen, 0)
+	c.Check(system.Right(), IsNil)
+	c.Check(system.Left(), IsNil)
+	_, _, _, _, found := system.DisplayTextImage()
+	c.Check(found, Equals, false)
+
+	// Nothing can load without a way to prepare the slides.
+	unprepared, err := NewSystem(testSystemConfig, affirmationFilename, filepath.Join(dir, "images")+"/")
+	c.Assert(err, IsNil)
+	_, err = unprepared.Load()
+	c.Check(err, ErrorEquals, `no slide preparer`)
+}
@@ -0,0 +1,70 @@
+package conditioning
+
+const (
+	// The effects given on an affirmation, or in the config, without their sizes.
+	_DEFAULT_SHADOW_OFFSET = 4  // Down and to the right.
+	_DEFAULT_SHADOW_BLUR   = 4  // How far the shadow softens.
+	_DEFAULT_GLOW_RADIUS   = 8  // How far the glow spreads.
+	_DEFAULT_PANEL_PADDING = 16 // Between the text and the edge of the panel.
+	_DEFAULT_PANEL_RADIUS  = 12 // How round the corners of the panel are.
+)
+
+// Shadow is a soft copy of the text drawn behind it, off to the side.
+type Shadow struct {
+	Color   string // The color of the shadow, a name or hex ("" for no shadow).
+	OffsetX int    // How far right of the text.
+	OffsetY int    // How far below the text.
+	Blur    uint   // How far the shadow softens, 0 for a sharp shadow.
+}
+
+// Glow is a soft halo around the text.
+type Glow struct {
+	Color  string // The color of the glow, a name or hex ("" for no glow).
+	Radius uint   // How far the glow spreads from the text.
+}
+
+// Panel is a box with rounded corners behind all the text.
+type Panel struct {
+	Color   string // The color of the panel, a name or hex ("" for no panel).
+	Padding uint   // Between the text and the edge of the panel.
+	Radius  uint   // How round the corners are.
+}
+
+// textEffects works out the effects behind some text, from its own settings or else the config.
+// Sizes not set in the config are the same as an effect given on an affirmation without them.
+func textEffects(config Config, textProperties TextProperties) (shadow Shadow, glow Glow, panel Panel) {
+	if config.ShadowColor != "" {
+		shadow = Shadow{Color: config.ShadowColor, OffsetX: config.ShadowOffsetX, OffsetY: config.ShadowOffsetY, Blur: config.ShadowBlur}
+		if shadow.OffsetX == 0 && shadow.OffsetY == 0 {
+			shadow.OffsetX, shadow.OffsetY = _DEFAULT_SHADOW_OFFSET, _DEFAULT_SHADOW_OFFSET
+		}
+		if shadow.Blur == 0 {
+			shadow.Blur = _DEFAULT_SHADOW_BLUR
+		}
+	}
+	if textProperties.Shadow.Color != "" {
+		shadow = textProperties.Shadow
+	}
+	if config.GlowColor != "" {
+		glow = Glow{Color: config.GlowColor, Radius: config.GlowRadius}
+		if glow.Radius == 0 {
+			glow.Radius = _DEFAULT_GLOW_RADIUS
+		}
+	}
+	if textProperties.Glow.Color != "" {
+		glow = textProperties.Glow
+	}
+	if config.PanelColor != "" {
+		panel = Panel{Color: config.PanelColor, Padding: config.PanelPadding, Radius: config.PanelRadius}
+		if panel.Padding == 0 {
+			panel.Padding = _DEFAULT_PANEL_PADDING
+		}
+		if panel.Radius == 0 {
+			panel.Radius = _DEFAULT_PANEL_RADIUS
+		}
+	}
+	if textProperties.Panel.Color != "" {
+		panel = textProperties.Panel
+	}
+	return shadow, glow, panel
+}
@@ -0,0 +1,44 @@
+package conditioning
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type TextEffectsSuite struct{}
+
+var _ = Suite(&TextEffectsSuite{})
+
+// Add the tests.
+
+func (s *TextEffectsSuite) Test_TextEffects(c *C) {
+
+	// Nothing unless set.
+	shadow, glow, panel := textEffects(Config{}, TextProperties{})
+	c.Check(shadow, Equals, Shadow{})
+	c.Check(glow, Equals, Glow{})
+	c.Check(panel, Equals, Panel{})
+
+	// The config sets the defaults.
+	config := Config{
+		ShadowColor: "black", ShadowOffsetX: 0, ShadowOffsetY: 6, ShadowBlur: 0,
+		GlowColor: "gold", GlowRadius: 10,
+		PanelColor: "#00000080", PanelPadding: 20, PanelRadius: 0,
+	}
+	shadow, glow, panel = textEffects(config, TextProperties{})
+	c.Check(shadow, Equals, Shadow{Color: "black", OffsetY: 6, Blur: 4})
+	c.Check(glow, Equals, Glow{Color: "gold", Radius: 10})
+	c.Check(panel, Equals, Panel{Color: "#00000080", Padding: 20, Radius: 12})
+
+	// Sizes not set are the defaults.
+	shadow, glow, panel = textEffects(Config{ShadowColor: "black", GlowColor: "gold", PanelColor: "white"}, TextProperties{})
+	c.Check(shadow, Equals, Shadow{Color: "black", OffsetX: 4, OffsetY: 4, Blur: 4})
+	c.Check(glow, Equals, Glow{Color: "gold", Radius: 8})
+	c.Check(panel, Equals, Panel{Color: "white", Padding: 16, Radius: 12})
+
+	// The affirmation wins, each effect as a whole.
+	shadow, glow, panel = textEffects(config, TextProperties{Shadow: Shadow{Color: "navy", OffsetX: 2, OffsetY: 2, Blur: 3}, Panel: Panel{Color: "white"}})
+	c.Check(shadow, Equals, Shadow{Color: "navy", OffsetX: 2, OffsetY: 2, Blur: 3})
+	c.Check(glow, Equals, Glow{Color: "gold", Radius: 10})
+	c.Check(panel, Equals, Panel{Color: "white"})
+}
@@ -0,0 +1,69 @@
+package conditioning
+
+const (
+	// How wrapped text lines up.
+	TEXT_ALIGN_CENTER  TextAlign = "center"  // Each line centered, the same as no alignment.
+	TEXT_ALIGN_LEFT    TextAlign = "left"    // Each line against the left.
+	TEXT_ALIGN_RIGHT   TextAlign = "right"   // Each line against the right.
+	TEXT_ALIGN_JUSTIFY TextAlign = "justify" // Each line but the last stretched to both sides.
+)
+
+// TextAlign is how wrapped text lines up.
+type TextAlign string
+
+// TextLayout is how text wraps and fits on the screen.
+type TextLayout struct {
+	MaxWidth    uint      // The widest the text can be before wrapping onto more lines, 0 to never wrap.
+	MaxHeight   uint      // The tallest the text can be when shrinking to fit, 0 for the screen height.
+	Align       TextAlign // How wrapped text lines up.
+	ShrinkToFit bool      // If true, lower the font size until the text fits.
+	NoShrink    bool      // If true, keep the font size even when the config shrinks text to fit.
+}
+
+// TextRun is a piece of laid out text, placed relative to the top left of the text.
+type TextRun struct {
+	X           int    // Coordinate from the left of the text.
+	Y           int    // Coordinate from the top of the text.
+	PangoMarkup string // The text to display with optional formatting.
+}
+
+// TextLayoutFor combines the configured text layout with an affirmation's own.
+func TextLayoutFor(config Config, textLayout TextLayout) (combined TextLayout) {
+	combined = TextLayout{
+		MaxWidth:    config.TextWidth,
+		MaxHeight:   config.TextHeight,
+		Align:       config.TextAlign,
+		ShrinkToFit: (config.ShrinkToFit || textLayout.ShrinkToFit) && !textLayout.NoShrink,
+	}
+	if textLayout.MaxWidth != 0 {
+		combined.MaxWidth = textLayout.MaxWidth
+	}
+	if textLayout.MaxHeight != 0 {
+		combined.MaxHeight = textLayout.MaxHeight
+	}
+	if textLayout.Align != "" {
+		combined.Align = textLayout.Align
+	}
+	return combined
+}
+
+// Box is the size text must fit in when shrinking.
+func (t TextLayout) Box(config Config) (width, height int) {
+	width, height = int(config.ScreenWidth), int(config.ScreenHeight)
+	if t.MaxWidth != 0 {
+		width = int(t.MaxWidth)
+	}
+	if t.MaxHeight != 0 {
+		height = int(t.MaxHeight)
+	}
+	return width, height
+}
+
+// validTextAlign checks a text alignment is one we know.
+func validTextAlign(align TextAlign) (valid bool) {
+	switch align {
+	case "", TEXT_ALIGN_CENTER, TEXT_ALIGN_LEFT, TEXT_ALIGN_RIGHT, TEXT_ALIGN_JUSTIFY:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package conditioning
+
+import (
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type TextLayoutSuite struct{}
+
+var _ = Suite(&TextLayoutSuite{})
+
+// Add the tests.
+
+func (s *TextLayoutSuite) Test_TextLayoutFor(c *C) {
+	config := Config{ScreenWidth: 1440, ScreenHeight: 900, TextWidth: 800, TextAlign: TEXT_ALIGN_LEFT}
+
+	// The config when the affirmation says nothing.
+	textLayout := TextLayoutFor(config, TextLayout{})
+	c.Check(textLayout, DeepEquals, TextLayout{MaxWidth: 800, Align: TEXT_ALIGN_LEFT})
+	width, height := textLayout.Box(config)
+	c.Check(width, Equals, 800)
+	c.Check(height, Equals, 900)
+
+	// The affirmation wins.
+	textLayout = TextLayoutFor(config, TextLayout{MaxWidth: 600, MaxHeight: 300, Align: TEXT_ALIGN_CENTER, ShrinkToFit: true})
+	c.Check(textLayout, DeepEquals, TextLayout{MaxWidth: 600, MaxHeight: 300, Align: TEXT_ALIGN_CENTER, ShrinkToFit: true})
+	width, height = textLayout.Box(config)
+	c.Check(width, Equals, 600)
+	c.Check(height, Equals, 300)
+
+	// The affirmation can keep its font size when the config shrinks text.
+	config.ShrinkToFit = true
+	c.Check(TextLayoutFor(config, TextLayout{}).ShrinkToFit, Equals, true)
+	c.Check(TextLayoutFor(config, TextLayout{NoShrink: true}).ShrinkToFit, Equals, false)
+}
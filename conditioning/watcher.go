@@ -0,0 +1,132 @@
+package conditioning
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// Watching for changes.
+	_WATCH_POLL_MILLI     = 500  // How often to look for changes.
+	_WATCH_DEBOUNCE_MILLI = 1000 // How long changes must settle before reloading.
+)
+
+// fileStamp is what a file looked like when it was last checked.
+type fileStamp struct {
+	modTime time.Time // When it was last written.
+	size    int64     // How big it is.
+}
+
+// Watch reloads the affirmations whenever the affirmations file or the images change, until stopped.
+// The files are polled, and a burst of changes (like an editor saving, or many images copied) reloads once they settle.
+func (s *System) Watch() (stop func()) {
+	doneChan := make(chan bool)
+	go s.watch(doneChan)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(doneChan) })
+	}
+}
+
+// watch polls the files for changes until told it is done.
+func (s *System) watch(doneChan chan bool) {
+	poll := _WATCH_POLL_MILLI * time.Millisecond
+	timer := s.clock.NewTimer(poll)
+	defer timer.Stop()
+
+	watcher := changeWatcher{debounce: _WATCH_DEBOUNCE_MILLI * time.Millisecond}
+	watcher.settled(watchedFiles(s.affirmationFilename, s.imagePath), s.clock.Now())
+
+	for { // Infinite loop.
+		select {
+
+		// Time to look again.
+		case <-timer.C():
+			if watcher.settled(watchedFiles(s.affirmationFilename, s.imagePath), s.clock.Now()) {
+				if _, err := s.Load(); err != nil {
+					log.Printf(`watch Load(): %+v`, err)
+				}
+			}
+			timer.Reset(poll)
+
+		// A stop command.
+		case <-doneChan:
+			return // kill the goroutine.
+		}
+	}
+}
+
+// changeWatcher debounces changes to files.
+type changeWatcher struct {
+	debounce  time.Duration        // How long changes must settle.
+	files     map[string]fileStamp // The files when last checked.
+	changedAt time.Time            // When a change was last seen.
+	pending   bool                 // If true, there are changes that haven't settled yet.
+}
+
+// settled checks the files, true once changes have stopped for the debounce time.
+func (c *changeWatcher) settled(files map[string]fileStamp, now time.Time) (settled bool) {
+
+	// The first look is how things start.
+	if c.files == nil {
+		c.files = files
+		return false
+	}
+
+	// Still changing?
+	if !sameFiles(c.files, files) {
+		c.files = files
+		c.changedAt = now
+		c.pending = true
+		return false
+	}
+
+	// Has it been quiet long enough?
+	if c.pending && now.Sub(c.changedAt) >= c.debounce {
+		c.pending = false
+		return true
+	}
+
+	return false
+}
+
+// sameFiles checks nothing has been added, removed or changed.
+func sameFiles(a, b map[string]fileStamp) (same bool) {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		if other, found := b[path]; !found || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// watchedFiles looks at the affirmations file and every file in the images folder.
+// Files that can't be looked at are left out, so they count as a change when they can be.
+func watchedFiles(affirmationFilename, imagePath string) (files map[string]fileStamp) {
+	files = map[string]fileStamp{}
+
+	// The affirmations.
+	if info, err := os.Stat(affirmationFilename); err == nil {
+		files[affirmationFilename] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	// The images, which may be in folders.
+	_ = filepath.Walk(imagePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip what can't be read.
+		}
+		if !info.IsDir() {
+			files[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+
+	return files
+}
@@ -0,0 +1,74 @@
+package conditioning
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1" // https://labix.org/gocheck
+)
+
+// Create a suite.
+type WatcherSuite struct{}
+
+var _ = Suite(&WatcherSuite{})
+
+// Add the tests.
+
+func (s *WatcherSuite) Test_Settled(c *C) {
+	start := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	at := func(milli int) (now time.Time) {
+		return start.Add(time.Duration(milli) * time.Millisecond)
+	}
+	before := map[string]fileStamp{"affirmations.txt": {modTime: start, size: 10}}
+	edited := map[string]fileStamp{"affirmations.txt": {modTime: at(100), size: 12}}
+	added := map[string]fileStamp{"affirmations.txt": {modTime: at(100), size: 12}, "images/calm.png": {modTime: at(200), size: 500}}
+
+	watcher := changeWatcher{debounce: time.Second}
+	tests := []struct {
+		files   map[string]fileStamp
+		milli   int
+		settled bool
+	}{
+		{before, 0, false},     // The first look.
+		{before, 500, false},   // Nothing changed.
+		{edited, 1000, false},  // Changed.
+		{edited, 1500, false},  // Still settling.
+		{added, 1800, false},   // Changed again, settling starts over.
+		{added, 2500, false},   // Still settling.
+		{added, 2800, true},    // Settled.
+		{added, 3500, false},   // Nothing new.
+		{before, 4000, false},  // Changed back.
+		{before, 10000, true},  // Settled.
+		{before, 20000, false}, // Nothing new.
+	}
+	for i, test := range tests {
+		c.Check(watcher.settled(test.files, at(test.milli)), Equals, test.settled, Commentf("Case %v: %v", i, test))
+	}
+}
+
+func (s *WatcherSuite) Test_WatchedFiles(c *C) {
+	dir, err := ioutil.TempDir("", "conditioning")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	affirmationFilename := filepath.Join(dir, "affirmations.txt")
+	imagePath := filepath.Join(dir, "images") + "/"
+
+	// Nothing there yet.
+	c.Check(watchedFiles(affirmationFilename, imagePath), DeepEquals, map[string]fileStamp{})
+
+	// The affirmations and images, including in folders.
+	c.Assert(ioutil.WriteFile(affirmationFilename, []byte("I am calm."), 0644), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(imagePath, "nature"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(imagePath, "calm.png"), []byte("png"), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(imagePath, "nature", "tree.png"), []byte("png"), 0644), IsNil)
+	files := watchedFiles(affirmationFilename, imagePath)
+	c.Check(len(files), Equals, 3)
+	c.Check(files[affirmationFilename].size, Equals, int64(10))
+	c.Check(sameFiles(files, watchedFiles(affirmationFilename, imagePath)), Equals, true)
+
+	// An edit is a change.
+	c.Assert(ioutil.WriteFile(affirmationFilename, []byte("I am calm and kind."), 0644), IsNil)
+	c.Check(sameFiles(files, watchedFiles(affirmationFilename, imagePath)), Equals, false)
+}